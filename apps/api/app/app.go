@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"pengi-med-saas/core/database"
+	"pengi-med-saas/core/envelope"
+	"pengi-med-saas/core/logger"
+	"pengi-med-saas/core/telemetry"
+	"pengi-med-saas/migrations"
+	"pengi-med-saas/routes"
+	"sync"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+/*
+App is the API server with everything it depends on, built once by New:
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := app.LoadConfig()
+	...
+	application, err := app.New(ctx, cfg)
+	...
+	err = application.Run(ctx)
+*/
+type App struct {
+	serverConfig ServerConfig
+	telemetry    *telemetry.Telemetry
+	db           *gorm.DB
+	services     routes.Services
+	server       *http.Server
+
+	// workers is the context background work runs under: the i18n cache
+	// listener and the replica health checks. stopWorkers cancels it.
+	workers     context.Context
+	stopWorkers context.CancelFunc
+	running     sync.WaitGroup
+}
+
+/*
+New sets up telemetry, connects to the database (running migrations when
+cfg.AutoMigrate is set) and builds the services and the HTTP server.
+Cancelling ctx aborts the connection retries.
+*/
+func New(ctx context.Context, cfg Config) (*App, error) {
+	envelope.SetProductionMode(cfg.Production)
+	envelope.SetProblemTypeBaseURI(cfg.ProblemTypeBaseURI)
+
+	var err error
+	a := &App{serverConfig: cfg.Server}
+	a.telemetry, err = telemetry.Setup(ctx, cfg.Telemetry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up telemetry: %w", err)
+	}
+
+	// Workers outlive ctx: they stop after the requests are drained, see Shutdown.
+	a.workers, a.stopWorkers = context.WithCancel(context.Background())
+	abortConnect := context.AfterFunc(ctx, a.stopWorkers)
+	a.db, err = database.ConnectWithConfig(a.workers, cfg.Database)
+	abortConnect()
+	if err != nil {
+		a.close(context.Background())
+		return nil, fmt.Errorf("failed to connect to the database: %w", err)
+	}
+
+	if cfg.AutoMigrate {
+		if err := migrations.RunAllMigrations(a.db); err != nil {
+			a.close(context.Background())
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+	} else {
+		logger.Info("Auto-migration disabled, skipping migrations")
+	}
+
+	a.services = routes.NewServices(a.db, cfg.Routes)
+	a.server = &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           routes.NewRouter(a.db, a.services, cfg.Routes, a.telemetry),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	return a, nil
+}
+
+/*
+Run starts the background workers and serves HTTP until ctx is cancelled
+(SIGTERM) or the server fails, then shuts down gracefully within
+SHUTDOWN_TIMEOUT.
+*/
+func (a *App) Run(ctx context.Context) error {
+	a.running.Go(func() {
+		a.services.MessageCache.Listen(a.workers)
+	})
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("HTTP server listening", zap.String("addr", a.server.Addr))
+		serveErr <- a.server.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+	case <-ctx.Done():
+		logger.Info("Shutdown signal received, draining requests",
+			zap.Duration("timeout", a.serverConfig.ShutdownTimeout))
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.serverConfig.ShutdownTimeout)
+	defer cancel()
+	return errors.Join(err, a.Shutdown(shutdownCtx))
+}
+
+/*
+Shutdown stops accepting connections and waits for in-flight requests, then
+stops the background workers, closes the database pool and flushes telemetry.
+Requests still running when ctx expires are cut off.
+*/
+func (a *App) Shutdown(ctx context.Context) error {
+	var errs []error
+	if err := a.server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain requests: %w", err))
+		a.server.Close()
+	}
+	errs = append(errs, a.close(ctx))
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	logger.Info("Shutdown complete")
+	return nil
+}
+
+// close releases what New acquired, in reverse order.
+func (a *App) close(ctx context.Context) error {
+	a.stopWorkers()
+	a.running.Wait()
+
+	var errs []error
+	if a.db != nil {
+		if sqlDB, err := a.db.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close the database: %w", err))
+			}
+		}
+	}
+	if err := a.telemetry.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush telemetry: %w", err))
+	}
+	return errors.Join(errs...)
+}
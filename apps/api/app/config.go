@@ -0,0 +1,92 @@
+package app
+
+import (
+	"pengi-med-saas/core/config"
+	"pengi-med-saas/core/database"
+	"pengi-med-saas/core/envelope"
+	"pengi-med-saas/core/telemetry"
+	"pengi-med-saas/routes"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+/*
+Config is the whole API configuration, loaded once at startup by LoadConfig
+and handed to New, which passes each package its own section.
+*/
+type Config struct {
+	// Production is GIN_MODE=release: 5xx messages are masked.
+	Production bool
+	// AutoMigrate runs pending migrations on startup. Production deploys set
+	// DB_AUTO_MIGRATE=false and run `main migrate up` as a separate step.
+	AutoMigrate bool
+	// ProblemTypeBaseURI prefixes the error code in problem+json responses.
+	ProblemTypeBaseURI string
+
+	Server    ServerConfig
+	Database  database.Config
+	Telemetry telemetry.Config
+	Routes    routes.Config
+}
+
+/*
+LoadConfig reads the configuration from environment variables, see
+config.LoadFiles for .env and YAML files:
+  - GIN_MODE, DB_AUTO_MIGRATE (true)
+  - PROBLEM_TYPE_BASE_URI ("urn:pengi-med-saas:error:")
+  - the LoadServerConfig, database.LoadConfig, telemetry.LoadConfig and
+    routes.LoadConfig variables
+
+Every missing or invalid value is reported in a single error.
+*/
+func LoadConfig() (Config, error) {
+	var l config.Loader
+	cfg := Config{
+		Production:         l.String("GIN_MODE", "") == "release",
+		AutoMigrate:        l.Bool("DB_AUTO_MIGRATE", true),
+		ProblemTypeBaseURI: l.String("PROBLEM_TYPE_BASE_URI", envelope.DefaultProblemTypeBaseURI),
+	}
+
+	var err error
+	cfg.Server, err = LoadServerConfig()
+	l.Add(err)
+	cfg.Database, err = database.LoadConfig()
+	l.Add(err)
+	cfg.Telemetry, err = telemetry.LoadConfig()
+	l.Add(err)
+	cfg.Routes, err = routes.LoadConfig()
+	l.Add(err)
+	return cfg, l.Err()
+}
+
+// MarshalLogObject logs the configuration at startup with the database
+// password, replica URLs and auth key left out.
+func (c Config) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddBool("production", c.Production)
+	enc.AddBool("auto_migrate", c.AutoMigrate)
+	enc.AddString("problem_type_base_uri", c.ProblemTypeBaseURI)
+
+	enc.AddString("addr", c.Server.Addr)
+	enc.AddDuration("read_timeout", c.Server.ReadTimeout)
+	enc.AddDuration("read_header_timeout", c.Server.ReadHeaderTimeout)
+	enc.AddDuration("write_timeout", c.Server.WriteTimeout)
+	enc.AddDuration("idle_timeout", c.Server.IdleTimeout)
+	enc.AddDuration("shutdown_timeout", c.Server.ShutdownTimeout)
+
+	enc.AddString("database", c.Database.String())
+	enc.AddInt("database_replicas", len(c.Database.Replicas))
+	enc.AddInt("database_max_open_conns", c.Database.MaxOpenConns)
+	enc.AddInt("database_max_idle_conns", c.Database.MaxIdleConns)
+
+	enc.AddString("otel_service_name", c.Telemetry.ServiceName)
+	enc.AddString("otel_traces_exporter", strings.Join(c.Telemetry.TracesExporters, ","))
+	enc.AddString("otel_metrics_exporter", strings.Join(c.Telemetry.MetricsExporters, ","))
+
+	enc.AddString("auth_key", c.Routes.Auth.Key.String())
+	enc.AddDuration("auth_token_ttl", c.Routes.Auth.TokenTTL)
+	enc.AddBool("auth_secure_cookies", c.Routes.Auth.SecureCookies)
+	enc.AddString("i18n_default_lang", c.Routes.DefaultLang)
+	enc.AddDuration("health_check_timeout", c.Routes.HealthCheckTimeout)
+	return nil
+}
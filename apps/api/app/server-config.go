@@ -0,0 +1,40 @@
+package app
+
+import (
+	"pengi-med-saas/core/config"
+	"time"
+)
+
+// ServerConfig holds the HTTP server settings.
+type ServerConfig struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout is how long in-flight requests get to finish after
+	// SIGTERM before their connections are closed.
+	ShutdownTimeout time.Duration
+}
+
+/*
+LoadServerConfig reads the HTTP server configuration from environment variables:
+  - PORT (8080)
+  - HTTP_READ_TIMEOUT (15s), HTTP_READ_HEADER_TIMEOUT (5s)
+  - HTTP_WRITE_TIMEOUT (30s), HTTP_IDLE_TIMEOUT (60s)
+  - SHUTDOWN_TIMEOUT (20s), keep it below the orchestrator's grace period
+
+All invalid values are reported together.
+*/
+func LoadServerConfig() (ServerConfig, error) {
+	var l config.Loader
+	cfg := ServerConfig{
+		Addr:              ":" + l.String("PORT", "8080"),
+		ReadTimeout:       l.Duration("HTTP_READ_TIMEOUT", 15*time.Second),
+		ReadHeaderTimeout: l.Duration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		WriteTimeout:      l.Duration("HTTP_WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:       l.Duration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+		ShutdownTimeout:   l.Duration("SHUTDOWN_TIMEOUT", 20*time.Second),
+	}
+	return cfg, l.Err()
+}
@@ -1,50 +1,88 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"pengi-med-saas/app"
+	"pengi-med-saas/core/config"
 	"pengi-med-saas/core/database"
 	"pengi-med-saas/core/logger"
-	"pengi-med-saas/features/health"
-	i18n_middleware "pengi-med-saas/i18n/middleware"
+	message_check "pengi-med-saas/i18n/check"
 	"pengi-med-saas/migrations"
-	"pengi-med-saas/routes"
+	"pengi-med-saas/seeds"
+	"syscall"
 
-	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
-var DB_CONNECTION *gorm.DB
-
 func main() {
-	mode := os.Getenv("GIN_MODE")
-	if mode == "release" {
+	// Variables already set win over .env, which wins over the YAML file.
+	var l config.Loader
+	filesErr := config.LoadFiles(l.String("ENV_FILE", ".env"), l.String("CONFIG_FILE", "config.yaml"))
+
+	production := l.String("GIN_MODE", "") == "release"
+	mode := "development"
+	if production {
 		mode = "production"
-	} else {
-		mode = "development"
 	}
 
 	logger.Init(mode)
+	if filesErr != nil {
+		logger.Fatal("Failed to read the configuration files", zap.Error(filesErr))
+	}
 	logger.Info("Starting application...", zap.String("env", mode))
 
-	DB_CONNECTION, err := database.Connect()
-	if err != nil {
-		panic("Failed to connect to the database: " + err.Error())
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrations.RunCommand(os.Args[2:], os.Stdout, databaseConnector()); err != nil {
+			logger.Fatal("Migration command failed", zap.Error(err))
+		}
+		return
 	}
 
-	err = migrations.RunAllMigrations(DB_CONNECTION)
-
-	if err != nil {
-		panic("Failed to run migrations: " + err.Error())
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if err := seeds.RunCommand(os.Args[2:], os.Stdout, databaseConnector(), production); err != nil {
+			logger.Fatal("Seed command failed", zap.Error(err))
+		}
+		return
 	}
 
-	r := gin.Default()
+	if len(os.Args) > 1 && os.Args[1] == "i18n" {
+		if err := message_check.RunCommand(os.Args[2:], os.Stdout); err != nil {
+			logger.Fatal("i18n command failed", zap.Error(err))
+		}
+		return
+	}
 
-	r.Use(i18n_middleware.I18nMiddleware(DB_CONNECTION))
+	cfg, err := app.LoadConfig()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	logger.Info("Configuration loaded", zap.Object("config", cfg))
 
-	r.GET("/health", health.Health)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	routes.RegisterRoutes(r.Group("/api"), DB_CONNECTION)
+	application, err := app.New(ctx, cfg)
+	if err != nil {
+		logger.Fatal("Failed to start the application", zap.Error(err))
+	}
+	if err := application.Run(ctx); err != nil {
+		logger.Fatal("Application stopped with errors", zap.Error(err))
+	}
+}
 
-	r.Run() // listen and serve on 0.0.0.0:8080
+// databaseConnector reads the database configuration for the migrate and seed
+// commands. An invalid one is only reported when they connect, so "migrate
+// create" works without it.
+func databaseConnector() func() (*gorm.DB, error) {
+	cfg, cfgErr := database.LoadConfig()
+	return func() (*gorm.DB, error) {
+		if cfgErr != nil {
+			return nil, fmt.Errorf("invalid database configuration: %w", cfgErr)
+		}
+		return database.ConnectWithConfig(context.Background(), cfg)
+	}
 }
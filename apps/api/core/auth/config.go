@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"pengi-med-saas/core/config"
+	"time"
+)
+
+// Config holds the token and cookie settings.
+type Config struct {
+	// Key signs and verifies every token.
+	Key config.Secret
+	// TokenTTL is the lifetime of access tokens.
+	TokenTTL time.Duration
+	// SecureCookies marks the refresh token cookie Secure, for HTTPS deploys.
+	SecureCookies bool
+}
+
+/*
+LoadConfig reads the auth configuration from environment variables:
+  - AUTH_KEY (required)
+  - AUTH_EXP, access token lifetime in minutes (30)
+  - HTTPS_ENABLED (false)
+
+All invalid values are reported together.
+*/
+func LoadConfig() (Config, error) {
+	var l config.Loader
+	cfg := Config{
+		Key:           l.Secret("AUTH_KEY"),
+		TokenTTL:      time.Duration(l.Int("AUTH_EXP", 30)) * time.Minute,
+		SecureCookies: l.Bool("HTTPS_ENABLED", false),
+	}
+	l.Check(cfg.TokenTTL > 0, "AUTH_EXP must be a positive number of minutes")
+	return cfg, l.Err()
+}
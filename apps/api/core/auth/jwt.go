@@ -2,7 +2,6 @@ package auth
 
 import (
 	"errors"
-	"pengi-med-saas/core/config"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,53 +13,51 @@ var (
 	ErrExpiredToken = errors.New("token is expired")
 )
 
-func GenerateToken(username string, userId int64) (string, error) {
-	secretKey := config.GetEnv("AUTH_KEY")
-	exp, err := config.GetNumberEnv("AUTH_EXP")
-	if err != nil {
-		return "", err
-	}
+// Tokens issues and verifies the JWTs and refresh token cookie with the
+// settings loaded at startup.
+type Tokens struct {
+	cfg Config
+}
+
+func NewTokens(cfg Config) *Tokens {
+	return &Tokens{cfg: cfg}
+}
+
+func (t *Tokens) GenerateToken(username string, userId int64) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"username": username,
 		"userId":   userId,
-		"exp":      time.Now().Add(time.Duration(exp) * time.Minute).Unix(),
+		"exp":      time.Now().Add(t.cfg.TokenTTL).Unix(),
 	})
-	return token.SignedString([]byte(secretKey))
-
+	return token.SignedString([]byte(t.cfg.Key.Value()))
 }
 
-func SetRefreshTokenCookie(refreshToken string, c *gin.Context) {
-	https_enabled, err := config.GetBoolEnv("HTTPS_ENABLED")
-	if err != nil {
-		return
-	}
+func (t *Tokens) SetRefreshTokenCookie(refreshToken string, c *gin.Context) {
 	c.SetCookie(
-		"refresh_token", // Nombre de la cookie
-		refreshToken,    // Valor de la cookie
-		604800,          // Tiempo de vida en segundos (1 hora)
-		"/",             // Path
-		"",              // Dominio
-		https_enabled,   // Habilitar Secure (solo HTTPS)
-		true,            // Habilitar HttpOnly
+		"refresh_token",     // Nombre de la cookie
+		refreshToken,        // Valor de la cookie
+		604800,              // Tiempo de vida en segundos (7 días)
+		"/",                 // Path
+		"",                  // Dominio
+		t.cfg.SecureCookies, // Habilitar Secure (solo HTTPS)
+		true,                // Habilitar HttpOnly
 	)
 }
 
-func GenerateRefreshToken(username string, userId int64) (string, error) {
-	secretKey := config.GetEnv("AUTH_KEY")
+func (t *Tokens) GenerateRefreshToken(username string, userId int64) (string, error) {
 	claims := jwt.MapClaims{
 		"username": username,
 		"userId":   userId,
 		"exp":      time.Now().Add(7 * 24 * time.Hour).Unix(), // Expira en 7 días.
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secretKey))
+	return token.SignedString([]byte(t.cfg.Key.Value()))
 }
 
-func ValidateRefreshToken(refreshToken string) (uint, string, error) {
-	secretKey := config.GetEnv("AUTH_KEY")
+func (t *Tokens) ValidateRefreshToken(refreshToken string) (uint, string, error) {
 	claims := jwt.MapClaims{}
 	token, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secretKey), nil
+		return []byte(t.cfg.Key.Value()), nil
 	})
 	if err != nil || !token.Valid {
 		return 0, "", errors.New("invalid refresh token")
@@ -86,12 +83,12 @@ func ValidateRefreshToken(refreshToken string) (uint, string, error) {
 	return uint(userId), username, nil
 }
 
-func ValidateCredentials(c *gin.Context) (bool, int64, error) {
+func (t *Tokens) ValidateCredentials(c *gin.Context) (bool, int64, error) {
 	authToken := c.Request.Header.Get("Authorization")
 	if authToken == "" {
 		return false, -1, ErrInvalidToken
 	}
-	claims, err := ParseToken(authToken)
+	claims, err := t.ParseToken(authToken)
 	if err != nil {
 		return false, -1, err
 	}
@@ -102,26 +99,25 @@ func ValidateCredentials(c *gin.Context) (bool, int64, error) {
 	return true, int64(userId), nil
 }
 
-func DecryptToken(c *gin.Context) (jwt.MapClaims, error) {
+func (t *Tokens) DecryptToken(c *gin.Context) (jwt.MapClaims, error) {
 	authToken := c.Request.Header.Get("Authorization")
 	if authToken == "" {
 		return nil, ErrInvalidToken
 	}
-	claims, err := ParseToken(authToken)
+	claims, err := t.ParseToken(authToken)
 	if err != nil {
 		return nil, err
 	}
 	return claims, nil
 }
 
-func ParseToken(token string) (jwt.MapClaims, error) {
-	secretKey := config.GetEnv("AUTH_KEY")
+func (t *Tokens) ParseToken(token string) (jwt.MapClaims, error) {
 	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
 			return nil, ErrInvalidToken
 		}
-		return []byte(secretKey), nil
+		return []byte(t.cfg.Key.Value()), nil
 	})
 	if err != nil || parsedToken == nil || !parsedToken.Valid {
 		return nil, ErrInvalidToken
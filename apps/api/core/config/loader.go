@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Loader reads typed values from the environment and collects every missing or
+invalid one, so startup reports all configuration errors at once:
+
+	var l config.Loader
+	cfg := Config{
+		Key:      l.Secret("AUTH_KEY"),
+		TokenTTL: time.Duration(l.Int("AUTH_EXP", 30)) * time.Minute,
+	}
+	return cfg, l.Err()
+*/
+type Loader struct {
+	errs []error
+}
+
+func (l *Loader) String(env, defaultValue string) string {
+	if val := os.Getenv(env); val != "" {
+		return val
+	}
+	return defaultValue
+}
+
+// List splits a comma separated value, dropping blank entries.
+func (l *Loader) List(env, defaultValue string) []string {
+	var list []string
+	for _, val := range strings.Split(l.String(env, defaultValue), ",") {
+		if val = strings.TrimSpace(val); val != "" {
+			list = append(list, val)
+		}
+	}
+	return list
+}
+
+// Required returns the value of env and records an error when it is empty.
+func (l *Loader) Required(env string) string {
+	val := strings.TrimSpace(os.Getenv(env))
+	if val == "" {
+		l.errs = append(l.errs, fmt.Errorf("%s is required", env))
+	}
+	return val
+}
+
+// Secret is Required for values that must be redacted in logs.
+func (l *Loader) Secret(env string) Secret {
+	return Secret(l.Required(env))
+}
+
+func (l *Loader) Int(env string, defaultValue int64) int64 {
+	raw := os.Getenv(env)
+	if raw == "" {
+		return defaultValue
+	}
+	val, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s must be an integer, got %q", env, raw))
+		return defaultValue
+	}
+	return val
+}
+
+func (l *Loader) Bool(env string, defaultValue bool) bool {
+	raw := os.Getenv(env)
+	if raw == "" {
+		return defaultValue
+	}
+	val, err := strconv.ParseBool(strings.ToLower(raw))
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s must be true or false, got %q", env, raw))
+		return defaultValue
+	}
+	return val
+}
+
+func (l *Loader) Duration(env string, defaultValue time.Duration) time.Duration {
+	raw := os.Getenv(env)
+	if raw == "" {
+		return defaultValue
+	}
+	val, err := time.ParseDuration(raw)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s must be a duration such as 30s or 5m, got %q", env, raw))
+		return defaultValue
+	}
+	return val
+}
+
+// Check records the error described by format unless ok holds.
+func (l *Loader) Check(ok bool, format string, args ...any) {
+	if !ok {
+		l.errs = append(l.errs, fmt.Errorf(format, args...))
+	}
+}
+
+// Add records err, typically from another section's loader; nil is ignored.
+func (l *Loader) Add(err error) {
+	if err != nil {
+		l.errs = append(l.errs, err)
+	}
+}
+
+// Err returns every recorded error joined, or nil.
+func (l *Loader) Err() error {
+	return errors.Join(l.errs...)
+}
@@ -0,0 +1,27 @@
+package config
+
+// redacted replaces secrets wherever they are printed.
+const redacted = "[REDACTED]"
+
+// Secret is a configuration value that must not end up in logs: it prints,
+// and marshals to JSON or YAML, as "[REDACTED]". Value returns the real one.
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"pengi-med-saas/core/config"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maintenanceDatabase = "postgres"
+
+/*
+Config holds the database connection settings.
+
+When URL (DATABASE_URL) is set it takes precedence over the individual
+DB_HOST/DB_PORT/DB_USER/DB_PASSWORD/DB_NAME variables. TLS, statement timeout
+and application name settings are added to either form unless the URL already
+sets them.
+*/
+type Config struct {
+	URL      string
+	Host     string
+	Port     string
+	User     string
+	Password config.Secret
+	Name     string
+
+	SSLMode     string
+	SSLRootCert string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// StatementTimeout bounds every statement except those run by migrations
+	// and seeds.
+	StatementTimeout time.Duration
+	ApplicationName  string
+
+	// Replicas are optional read-replica connection URLs (DB_REPLICA_URLS).
+	Replicas             []string
+	ReplicaMaxLag        time.Duration
+	ReplicaCheckInterval time.Duration
+
+	// CreateDatabase creates Name on the server when it doesn't exist.
+	CreateDatabase bool
+	// ConnectRetries is how many times a failed connection is retried, with
+	// exponential backoff starting at ConnectBackoff.
+	ConnectRetries int
+	ConnectBackoff time.Duration
+}
+
+/*
+LoadConfig reads the database configuration from environment variables:
+  - DATABASE_URL, or DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME
+  - DB_SSLMODE (default "prefer"), DB_SSL_ROOT_CERT
+  - DB_MAX_OPEN_CONNS (25), DB_MAX_IDLE_CONNS (5)
+  - DB_CONN_MAX_LIFETIME (30m), DB_CONN_MAX_IDLE_TIME (5m)
+  - DB_STATEMENT_TIMEOUT (disabled), DB_APPLICATION_NAME ("pengi-med-saas")
+  - DB_CREATE_DATABASE (true), DB_CONNECT_RETRIES (5), DB_CONNECT_BACKOFF (1s)
+  - DB_REPLICA_URLS (comma separated), DB_REPLICA_MAX_LAG (10s),
+    DB_REPLICA_CHECK_INTERVAL (5s)
+
+All invalid values are reported together.
+*/
+func LoadConfig() (Config, error) {
+	var l config.Loader
+	cfg := Config{
+		URL:             l.String("DATABASE_URL", ""),
+		Host:            l.String("DB_HOST", ""),
+		Port:            l.String("DB_PORT", ""),
+		User:            l.String("DB_USER", ""),
+		Password:        config.Secret(l.String("DB_PASSWORD", "")),
+		Name:            l.String("DB_NAME", ""),
+		SSLMode:         l.String("DB_SSLMODE", "prefer"),
+		SSLRootCert:     l.String("DB_SSL_ROOT_CERT", ""),
+		ApplicationName: l.String("DB_APPLICATION_NAME", "pengi-med-saas"),
+
+		MaxOpenConns:     int(l.Int("DB_MAX_OPEN_CONNS", 25)),
+		MaxIdleConns:     int(l.Int("DB_MAX_IDLE_CONNS", 5)),
+		ConnMaxLifetime:  l.Duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		ConnMaxIdleTime:  l.Duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		StatementTimeout: l.Duration("DB_STATEMENT_TIMEOUT", 0),
+
+		Replicas:             l.List("DB_REPLICA_URLS", ""),
+		ReplicaMaxLag:        l.Duration("DB_REPLICA_MAX_LAG", 10*time.Second),
+		ReplicaCheckInterval: l.Duration("DB_REPLICA_CHECK_INTERVAL", 5*time.Second),
+
+		CreateDatabase: l.Bool("DB_CREATE_DATABASE", true),
+		ConnectRetries: int(l.Int("DB_CONNECT_RETRIES", 5)),
+		ConnectBackoff: l.Duration("DB_CONNECT_BACKOFF", time.Second),
+	}
+	l.Add(cfg.Validate())
+	return cfg, l.Err()
+}
+
+// Validate checks that the configuration can produce a usable DSN.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("DATABASE_URL is not a valid URL: %w", err))
+		} else if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+			errs = append(errs, fmt.Errorf("DATABASE_URL must use the postgres:// scheme"))
+		} else if strings.TrimPrefix(u.Path, "/") == "" {
+			errs = append(errs, fmt.Errorf("DATABASE_URL must include a database name"))
+		}
+	} else {
+		var missing []string
+		for env, val := range map[string]string{
+			"DB_HOST":     c.Host,
+			"DB_PORT":     c.Port,
+			"DB_USER":     c.User,
+			"DB_PASSWORD": c.Password.Value(),
+			"DB_NAME":     c.Name,
+		} {
+			if val == "" {
+				missing = append(missing, env)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			errs = append(errs, fmt.Errorf("missing required environment variables for database connection: %s", strings.Join(missing, ", ")))
+		}
+	}
+
+	switch c.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("DB_SSLMODE %q is not a valid sslmode", c.SSLMode))
+	}
+	if (c.SSLMode == "verify-ca" || c.SSLMode == "verify-full") && c.SSLRootCert == "" && !c.urlHas("sslrootcert") {
+		errs = append(errs, fmt.Errorf("DB_SSL_ROOT_CERT is required with DB_SSLMODE=%s", c.SSLMode))
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("database pool sizes can't be negative"))
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) can't exceed DB_MAX_OPEN_CONNS (%d)", c.MaxIdleConns, c.MaxOpenConns))
+	}
+	for i, replica := range c.Replicas {
+		u, err := url.Parse(replica)
+		if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") || strings.TrimPrefix(u.Path, "/") == "" {
+			errs = append(errs, fmt.Errorf("DB_REPLICA_URLS entry %d must be a postgres:// URL with a database name", i+1))
+		}
+	}
+	if len(c.Replicas) > 0 && c.ReplicaCheckInterval <= 0 {
+		errs = append(errs, fmt.Errorf("DB_REPLICA_CHECK_INTERVAL must be positive"))
+	}
+	if c.ConnectRetries < 0 {
+		errs = append(errs, fmt.Errorf("DB_CONNECT_RETRIES can't be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// DatabaseName returns the name of the application database.
+func (c Config) DatabaseName() string {
+	if c.URL != "" {
+		if u, err := url.Parse(c.URL); err == nil {
+			return strings.TrimPrefix(u.Path, "/")
+		}
+	}
+	return c.Name
+}
+
+// DSN returns the connection string for the application database.
+func (c Config) DSN() string {
+	return c.dsn(c.DatabaseName())
+}
+
+// maintenanceDSN returns a connection string for the server's maintenance
+// database, used to create the application database.
+func (c Config) maintenanceDSN() string {
+	return c.dsn(maintenanceDatabase)
+}
+
+func (c Config) dsn(dbname string) string {
+	params := c.params()
+
+	if c.URL != "" {
+		u, _ := url.Parse(c.URL)
+		u.Path = "/" + dbname
+		query := u.Query()
+		for _, key := range sortedKeys(params) {
+			if !query.Has(key) {
+				query.Set(key, params[key])
+			}
+		}
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+
+	params["host"] = c.Host
+	params["port"] = c.Port
+	params["user"] = c.User
+	params["password"] = c.Password.Value()
+	params["dbname"] = dbname
+
+	parts := make([]string, 0, len(params))
+	for _, key := range sortedKeys(params) {
+		parts = append(parts, key+"="+quoteDSNValue(params[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// params returns the connection parameters shared by both DSN forms.
+func (c Config) params() map[string]string {
+	params := map[string]string{"sslmode": c.SSLMode}
+	if c.SSLRootCert != "" {
+		params["sslrootcert"] = c.SSLRootCert
+	}
+	if c.ApplicationName != "" {
+		params["application_name"] = c.ApplicationName
+	}
+	if c.StatementTimeout > 0 {
+		params["statement_timeout"] = fmt.Sprint(c.StatementTimeout.Milliseconds())
+	}
+	return params
+}
+
+func (c Config) urlHas(key string) bool {
+	if c.URL == "" {
+		return false
+	}
+	u, err := url.Parse(c.URL)
+	return err == nil && u.Query().Has(key)
+}
+
+// String returns the DSN with the password redacted, safe for logs.
+func (c Config) String() string {
+	redacted := c
+	if redacted.Password != "" {
+		redacted.Password = "xxxxx"
+	}
+	if redacted.URL != "" {
+		if u, err := url.Parse(redacted.URL); err == nil {
+			redacted.URL = u.Redacted()
+		}
+	}
+	return redacted.DSN()
+}
+
+// quoteDSNValue quotes a keyword/value DSN value when needed.
+func quoteDSNValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
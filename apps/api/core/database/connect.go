@@ -1,71 +1,112 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"pengi-med-saas/core/config"
+	"pengi-med-saas/core/logger"
+	"time"
 
-	_ "github.com/lib/pq" // driver PostgreSQL
+	"github.com/jackc/pgx/v5"
+	_ "github.com/jackc/pgx/v5/stdlib" // driver PostgreSQL
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// maxConnectBackoff caps the delay between connection attempts.
+const maxConnectBackoff = 30 * time.Second
+
 /*
-Connect establishes a connection to the database using environment variables.
-It returns a gorm.DB instance or an error if the connection fails.
-
-It uses the PostgreSQL driver and requires the following environment variables:
-- DB_HOST: The database host
-- DB_PORT: The database port
-- DB_USER: The database user
-- DB_PASSWORD: The database password
-- DB_NAME: The database name
-If any of these variables are not set, it will return an error.
+ConnectWithConfig connects to the database described by cfg, creating it first
+when cfg.CreateDatabase is set, and applies the pool settings. Read replicas,
+when configured, are health-checked until ctx is done.
+
+Failed attempts are retried cfg.ConnectRetries times with exponential backoff,
+so the API can start while Postgres is still coming up.
 */
-func Connect() (*gorm.DB, error) {
-	if err := EnsureDatabase(); err != nil {
+func ConnectWithConfig(ctx context.Context, cfg Config) (*gorm.DB, error) {
+	backoff := cfg.ConnectBackoff
+	for attempt := 0; ; attempt++ {
+		db, err := connect(ctx, cfg)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			return nil, fmt.Errorf("failed to connect to the database after %d attempts: %w", attempt+1, err)
+		}
+
+		logger.Warn("Database connection failed, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+func connect(ctx context.Context, cfg Config) (*gorm.DB, error) {
+	if cfg.CreateDatabase {
+		if err := EnsureDatabase(ctx, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{})
+	if err != nil {
 		return nil, err
 	}
-	host := config.GetEnv("DB_HOST")
-	port := config.GetEnv("DB_PORT")
-	user := config.GetEnv("DB_USER")
-	password := config.GetEnv("DB_PASSWORD")
-	dbname := config.GetEnv("DB_NAME")
-
-	if host == "" || port == "" || user == "" || password == "" || dbname == "" {
-		return nil, fmt.Errorf("missing required environment variables for database connection")
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
 	}
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", host, port, user, password, dbname)
-	return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	stopReplicas := func() {}
+	if len(cfg.Replicas) > 0 {
+		if stopReplicas, err = setupReplicas(ctx, db, cfg); err != nil {
+			sqlDB.Close()
+			return nil, err
+		}
+	}
+
+	if err := registerTracingCallbacks(db); err != nil {
+		stopReplicas()
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to instrument the database: %w", err)
+	}
+	if _, err := registerPoolMetrics(sqlDB, "primary"); err != nil {
+		stopReplicas()
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to instrument the database: %w", err)
+	}
+	return db, nil
 }
 
 /*
-	EnsureDatabase checks if the specified database exists, and creates it if it does not.
-
-It uses environment variables to get the database connection parameters:
-- DB_HOST: The database host
-- DB_PORT: The database port
-- DB_USER: The database user
-- DB_PASSWORD: The database password
-- DB_NAME: The database name
-If any of these variables are not set, it will return an error.
+EnsureDatabase checks if the configured database exists, and creates it if it
+does not. It connects to the server's maintenance database with the same
+credentials and TLS settings as the application connection.
 */
-func EnsureDatabase() error {
-	host := config.GetEnv("DB_HOST")
-	port := config.GetEnv("DB_PORT")
-	user := config.GetEnv("DB_USER")
-	password := config.GetEnv("DB_PASSWORD")
-	dbname := config.GetEnv("DB_NAME")
-
-	if host == "" || port == "" || user == "" || password == "" || dbname == "" {
-		return fmt.Errorf("missing required environment variables for database connection")
-	}
+func EnsureDatabase(ctx context.Context, cfg Config) error {
+	dbname := cfg.DatabaseName()
 
-	// 1️⃣ Conexión temporal sin especificar la base de datos
-	rootDSN := fmt.Sprintf("host=%s port=%s user=%s password=%s sslmode=disable", host, port, user, password)
-	rootDB, err := sql.Open("postgres", rootDSN)
+	// 1️⃣ Conexión temporal a la base de mantenimiento
+	rootDB, err := sql.Open("pgx", cfg.maintenanceDSN())
 	if err != nil {
 		return fmt.Errorf("error connecting to postgres server: %w", err)
 	}
@@ -73,20 +114,19 @@ func EnsureDatabase() error {
 
 	// 2️⃣ Verificar si la base ya existe
 	var exists bool
-	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = '%s');", dbname)
-	if err := rootDB.QueryRow(query).Scan(&exists); err != nil {
+	query := "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)"
+	if err := rootDB.QueryRowContext(ctx, query, dbname).Scan(&exists); err != nil {
 		return fmt.Errorf("error checking database existence: %w", err)
 	}
 
 	// 3️⃣ Crear si no existe
 	if !exists {
-		_, err = rootDB.Exec(fmt.Sprintf("CREATE DATABASE %s;", dbname))
-		if err != nil {
+		if _, err := rootDB.ExecContext(ctx, "CREATE DATABASE "+pgx.Identifier{dbname}.Sanitize()); err != nil {
 			return fmt.Errorf("error creating database %s: %w", dbname, err)
 		}
-		log.Printf("✅ Database %s created successfully\n", dbname)
+		logger.Info("Database created successfully", zap.String("database", dbname))
 	} else {
-		log.Printf("ℹ️ Database %s already exists\n", dbname)
+		logger.Debug("Database already exists", zap.String("database", dbname))
 	}
 
 	return nil
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// noTransactionDirective marks a SQL migration that must run outside a
+// transaction (e.g. CREATE INDEX CONCURRENTLY). It must appear on its own line.
+const noTransactionDirective = "-- migrate:no-transaction"
+
+// MigrationFunc applies or reverts a migration written in Go.
+type MigrationFunc func(tx *gorm.DB) error
+
+/*
+Migration is a single versioned schema change.
+
+A migration is either SQL based (UpSQL/DownSQL, loaded from embedded files) or
+Go based (Up/Down). Versions are ordered numerically, so the usual convention is
+a UTC timestamp like 20260301120000.
+*/
+type Migration struct {
+	Version       int64
+	Name          string
+	UpSQL         string
+	DownSQL       string
+	Up            MigrationFunc
+	Down          MigrationFunc
+	NoTransaction bool
+}
+
+// ID returns the canonical "<version>_<name>" identifier of the migration.
+func (m Migration) ID() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// HasDown reports whether the migration can be reverted.
+func (m Migration) HasDown() bool {
+	return m.Down != nil || strings.TrimSpace(m.DownSQL) != ""
+}
+
+/*
+Checksum returns a stable hash of the migration contents.
+
+For SQL migrations the hash covers both the up and down scripts, so editing an
+already applied file is detected. Go migrations can't be hashed by content, so
+their checksum only covers the identifier.
+*/
+func (m Migration) Checksum() string {
+	h := sha256.New()
+	if m.Up != nil || m.Down != nil {
+		h.Write([]byte("go:" + m.ID()))
+	} else {
+		h.Write([]byte(m.UpSQL))
+		h.Write([]byte{0})
+		h.Write([]byte(m.DownSQL))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (m Migration) runUp(tx *gorm.DB) error {
+	if m.Up != nil {
+		return m.Up(tx)
+	}
+	return tx.Exec(m.UpSQL).Error
+}
+
+func (m Migration) runDown(tx *gorm.DB) error {
+	if m.Down != nil {
+		return m.Down(tx)
+	}
+	return tx.Exec(m.DownSQL).Error
+}
+
+// SchemaMigration is the tracking row stored for every applied migration.
+type SchemaMigration struct {
+	Version     int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name        string    `gorm:"type:varchar(255);not null"`
+	Checksum    string    `gorm:"type:varchar(64);not null"`
+	AppliedAt   time.Time `gorm:"not null"`
+	ExecutionMs int64     `gorm:"not null;default:0"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+/*
+LoadSQLMigrations reads migrations from dir inside fsys.
+
+Files must be named "<version>_<name>.up.sql" and "<version>_<name>.down.sql".
+A down file is optional; a down file without its up counterpart is an error.
+*/
+func LoadSQLMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations directory %s: %w", dir, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		version, name, direction, err := ParseMigrationFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		switch direction {
+		case "up":
+			m.UpSQL = string(content)
+			m.NoTransaction = hasNoTransactionDirective(m.UpSQL)
+		case "down":
+			m.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.UpSQL) == "" {
+			return nil, fmt.Errorf("migration %s has no up script", m.ID())
+		}
+		migrations = append(migrations, *m)
+	}
+	SortMigrations(migrations)
+	return migrations, nil
+}
+
+// ParseMigrationFileName splits "<version>_<name>.<up|down>.sql" into its parts.
+func ParseMigrationFileName(fileName string) (int64, string, string, error) {
+	base := strings.TrimSuffix(fileName, ".sql")
+	direction := path.Ext(base)
+	if direction != ".up" && direction != ".down" {
+		return 0, "", "", fmt.Errorf("migration %s must end in .up.sql or .down.sql", fileName)
+	}
+	base = strings.TrimSuffix(base, direction)
+
+	versionStr, name, found := strings.Cut(base, "_")
+	if !found || name == "" {
+		return 0, "", "", fmt.Errorf("migration %s must be named <version>_<name>", fileName)
+	}
+	version, err := strconv.ParseInt(versionStr, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("migration %s has an invalid version %q", fileName, versionStr)
+	}
+
+	return version, name, strings.TrimPrefix(direction, "."), nil
+}
+
+// SortMigrations orders migrations by ascending version.
+func SortMigrations(migrations []Migration) {
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
+
+func hasNoTransactionDirective(sql string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		if strings.TrimSpace(line) == noTransactionDirective {
+			return true
+		}
+	}
+	return false
+}
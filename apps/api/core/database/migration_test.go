@@ -0,0 +1,167 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestParseMigrationFileName(t *testing.T) {
+	tests := []struct {
+		file      string
+		version   int64
+		name      string
+		direction string
+	}{
+		{"20260301120000_create_users.up.sql", 20260301120000, "create_users", "up"},
+		{"20260301120000_create_users.down.sql", 20260301120000, "create_users", "down"},
+		{"1_init.up.sql", 1, "init", "up"},
+		{"0042_add_index.up.sql", 42, "add_index", "up"},
+		{"20260301120000_add_v2.5_columns.up.sql", 20260301120000, "add_v2.5_columns", "up"},
+	}
+	for _, tt := range tests {
+		version, name, direction, err := ParseMigrationFileName(tt.file)
+		if err != nil {
+			t.Errorf("ParseMigrationFileName(%q) error: %v", tt.file, err)
+			continue
+		}
+		if version != tt.version || name != tt.name || direction != tt.direction {
+			t.Errorf("ParseMigrationFileName(%q) = %d, %q, %q; want %d, %q, %q",
+				tt.file, version, name, direction, tt.version, tt.name, tt.direction)
+		}
+	}
+
+	for _, file := range []string{
+		"20260301120000_create_users.sql",
+		"20260301120000_create_users.sideways.sql",
+		"20260301120000.up.sql",
+		"20260301120000_.up.sql",
+		"create_users.up.sql",
+		"0_init.up.sql",
+		"-1_init.up.sql",
+		"v1_init.up.sql",
+	} {
+		if _, _, _, err := ParseMigrationFileName(file); err == nil {
+			t.Errorf("ParseMigrationFileName(%q) succeeded, want an error", file)
+		}
+	}
+}
+
+func TestLoadSQLMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/10_add_index.up.sql":     {Data: []byte("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY idx ON users (email);\n")},
+		"sql/10_add_index.down.sql":   {Data: []byte("DROP INDEX CONCURRENTLY idx;")},
+		"sql/9_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id bigserial PRIMARY KEY);")},
+		"sql/9_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"sql/100_backfill.up.sql":     {Data: []byte("UPDATE users SET email = lower(email); -- migrate:no-transaction")},
+		"sql/README.md":               {Data: []byte("not a migration")},
+		"sql/archive/1_old.up.sql":    {Data: []byte("SELECT 1;")},
+		"elsewhere/5_ignored.up.sql":  {Data: []byte("SELECT 1;")},
+	}
+
+	migrations, err := LoadSQLMigrations(fsys, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 9, Name: "create_users", UpSQL: "CREATE TABLE users (id bigserial PRIMARY KEY);", DownSQL: "DROP TABLE users;"},
+		{Version: 10, Name: "add_index", UpSQL: "-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY idx ON users (email);\n", DownSQL: "DROP INDEX CONCURRENTLY idx;", NoTransaction: true},
+		// The directive only counts on its own line.
+		{Version: 100, Name: "backfill", UpSQL: "UPDATE users SET email = lower(email); -- migrate:no-transaction"},
+	}
+	if !reflect.DeepEqual(migrations, want) {
+		t.Errorf("LoadSQLMigrations =\n%+v\nwant\n%+v", migrations, want)
+	}
+	if migrations[2].HasDown() {
+		t.Error("a migration without a down file has a down script")
+	}
+}
+
+func TestLoadSQLMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			"down without up",
+			fstest.MapFS{"sql/1_init.down.sql": {Data: []byte("DROP TABLE t;")}},
+			"has no up script",
+		},
+		{
+			"empty up",
+			fstest.MapFS{"sql/1_init.up.sql": {Data: []byte("  \n")}},
+			"has no up script",
+		},
+		{
+			"duplicate version",
+			fstest.MapFS{
+				"sql/1_init.up.sql":  {Data: []byte("SELECT 1;")},
+				"sql/1_other.up.sql": {Data: []byte("SELECT 2;")},
+			},
+			"conflicting names",
+		},
+		{
+			"bad name",
+			fstest.MapFS{"sql/init.up.sql": {Data: []byte("SELECT 1;")}},
+			"<version>_<name>",
+		},
+		{
+			"missing directory",
+			fstest.MapFS{},
+			"error reading migrations directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadSQLMigrations(tt.fsys, "sql")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrationChecksum(t *testing.T) {
+	base := Migration{Version: 1, Name: "init", UpSQL: "CREATE TABLE t ();", DownSQL: "DROP TABLE t;"}
+	if base.Checksum() != base.Checksum() {
+		t.Fatal("checksum is not stable")
+	}
+
+	changed := map[string]Migration{
+		"up":       {Version: 1, Name: "init", UpSQL: "CREATE TABLE t (id int);", DownSQL: base.DownSQL},
+		"down":     {Version: 1, Name: "init", UpSQL: base.UpSQL, DownSQL: "DROP TABLE IF EXISTS t;"},
+		"boundary": {Version: 1, Name: "init", UpSQL: base.UpSQL + "DROP", DownSQL: " TABLE t;"},
+	}
+	for name, m := range changed {
+		if m.Checksum() == base.Checksum() {
+			t.Errorf("changing the %s script kept the checksum", name)
+		}
+	}
+
+	noop := func(*gorm.DB) error { return nil }
+	goMigration := Migration{Version: 2, Name: "backfill", Up: noop}
+	renamed := Migration{Version: 2, Name: "backfill_emails", Up: noop}
+	if goMigration.Checksum() == renamed.Checksum() {
+		t.Error("Go migrations with different IDs share a checksum")
+	}
+	if goMigration.HasDown() {
+		t.Error("a Go migration without Down has a down script")
+	}
+}
+
+func TestNewMigratorDuplicateVersions(t *testing.T) {
+	_, err := NewMigrator(nil, zap.NewNop(),
+		Migration{Version: 2, Name: "second", UpSQL: "SELECT 2;"},
+		Migration{Version: 1, Name: "first", UpSQL: "SELECT 1;"},
+		Migration{Version: 2, Name: "again", UpSQL: "SELECT 2;"},
+	)
+	if err == nil || !strings.Contains(err.Error(), "duplicated migration version 2") {
+		t.Errorf("error = %v, want a duplicated version error", err)
+	}
+}
@@ -0,0 +1,458 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gorm_logger "gorm.io/gorm/logger"
+)
+
+// migrationLockKey is the Postgres advisory lock key shared by every replica
+// running migrations against the same database.
+const migrationLockKey int64 = 0x70656e67696d6967 // "pengimig"
+
+var (
+	ErrMigrationChecksum = errors.New("applied migration was modified")
+	ErrMigrationNoDown   = errors.New("migration has no down script")
+)
+
+// MigrationStatus describes a known migration and whether it has been applied.
+type MigrationStatus struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt time.Time
+	// Modified is true when the applied checksum differs from the source.
+	Modified bool
+	// Missing is true when the migration is recorded in the database but no
+	// longer present in the source tree.
+	Missing bool
+}
+
+// Migrator applies versioned migrations and records them in schema_migrations.
+type Migrator struct {
+	db         *gorm.DB
+	logger     *zap.Logger
+	migrations []Migration
+	dryRun     io.Writer
+}
+
+/*
+NewMigrator returns a Migrator for the given migrations.
+
+Migrations are sorted by version; duplicated versions are rejected.
+*/
+func NewMigrator(db *gorm.DB, logger *zap.Logger, migrations ...Migration) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	SortMigrations(sorted)
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("duplicated migration version %d (%s, %s)", sorted[i].Version, sorted[i-1].Name, sorted[i].Name)
+		}
+	}
+
+	return &Migrator{
+		db:         Primary(db),
+		logger:     logger,
+		migrations: sorted,
+	}, nil
+}
+
+/*
+DryRun returns a copy of the Migrator that writes the SQL of every migration it
+would apply or revert to w instead of executing it. Go migrations run against a
+GORM dry-run session, so only the statements they build are printed.
+*/
+func (m *Migrator) DryRun(w io.Writer) *Migrator {
+	clone := *m
+	clone.dryRun = w
+	return &clone
+}
+
+// Migrations returns the known migrations in version order.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+/*
+Up applies pending migrations in version order.
+
+When n > 0 at most n migrations are applied. It returns the migrations that ran.
+It fails before applying anything if an applied migration was modified.
+*/
+func (m *Migrator) Up(ctx context.Context, n int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func() error {
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+		statuses, err := m.status(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkModified(statuses); err != nil {
+			return err
+		}
+
+		for _, s := range statuses {
+			if s.Applied || s.Missing {
+				continue
+			}
+			if n > 0 && len(applied) >= n {
+				break
+			}
+			if err := m.apply(ctx, s.Migration); err != nil {
+				return err
+			}
+			applied = append(applied, s.Migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+/*
+Down reverts the last n applied migrations, newest first.
+
+When n <= 0 only the latest migration is reverted. It returns the migrations
+that were reverted.
+*/
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	if n <= 0 {
+		n = 1
+	}
+
+	var reverted []Migration
+	err := m.withLock(ctx, func() error {
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+		var err error
+		reverted, err = m.down(ctx, n)
+		return err
+	})
+	return reverted, err
+}
+
+/*
+Redo reverts the latest applied migration and applies it again, holding the
+migration lock for both steps. It returns the migration that was redone.
+*/
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := m.withLock(ctx, func() error {
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+		reverted, err := m.down(ctx, 1)
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			return nil
+		}
+		redone = &reverted[0]
+		return m.apply(ctx, *redone)
+	})
+	return redone, err
+}
+
+func (m *Migrator) down(ctx context.Context, n int) ([]Migration, error) {
+	statuses, err := m.status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkModified(statuses); err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(statuses) - 1; i >= 0 && len(reverted) < n; i-- {
+		s := statuses[i]
+		if !s.Applied {
+			continue
+		}
+		if s.Missing {
+			return reverted, fmt.Errorf("cannot revert migration %s: not present in source", s.Migration.ID())
+		}
+		if err := m.revert(ctx, s.Migration); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, s.Migration)
+	}
+	return reverted, nil
+}
+
+/*
+Status returns every known or recorded migration in version order. It does not
+take the migration lock, so it answers right away while another replica is
+migrating; migrations applied in the meantime show up as soon as they commit.
+*/
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	return m.status(ctx)
+}
+
+/*
+Pending returns the known migrations that are not applied yet. Like Status it
+does not take the migration lock, so readiness probes can call it while
+another replica is migrating.
+*/
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range statuses {
+		if !s.Applied {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migrator) status(ctx context.Context) ([]MigrationStatus, error) {
+	var records []SchemaMigration
+	db := m.db.WithContext(ctx)
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		if err := db.Order("version").Find(&records).Error; err != nil {
+			return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+	}
+
+	recorded := make(map[int64]SchemaMigration, len(records))
+	for _, r := range records {
+		recorded[r.Version] = r
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		s := MigrationStatus{Migration: migration}
+		if r, ok := recorded[migration.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = r.AppliedAt
+			s.Modified = r.Checksum != migration.Checksum()
+			delete(recorded, migration.Version)
+		}
+		statuses = append(statuses, s)
+	}
+
+	for _, r := range recorded {
+		statuses = append(statuses, MigrationStatus{
+			Migration: Migration{Version: r.Version, Name: r.Name},
+			Applied:   true,
+			AppliedAt: r.AppliedAt,
+			Missing:   true,
+		})
+	}
+
+	sortStatuses(statuses)
+	return statuses, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	if m.dryRun != nil {
+		return m.print(ctx, "up", migration, migration.UpSQL, migration.Up)
+	}
+
+	start := time.Now()
+	record := func(db *gorm.DB) error {
+		return db.Create(&SchemaMigration{
+			Version:     migration.Version,
+			Name:        migration.Name,
+			Checksum:    migration.Checksum(),
+			AppliedAt:   time.Now().UTC(),
+			ExecutionMs: time.Since(start).Milliseconds(),
+		}).Error
+	}
+
+	err := m.run(ctx, migration, func(tx *gorm.DB) error {
+		if err := migration.runUp(tx); err != nil {
+			return err
+		}
+		return record(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", migration.ID(), err)
+	}
+
+	m.logger.Info("Migration applied",
+		zap.String("migration", migration.ID()),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, migration Migration) error {
+	if !migration.HasDown() {
+		return fmt.Errorf("cannot revert migration %s: %w", migration.ID(), ErrMigrationNoDown)
+	}
+	if m.dryRun != nil {
+		return m.print(ctx, "down", migration, migration.DownSQL, migration.Down)
+	}
+
+	start := time.Now()
+	err := m.run(ctx, migration, func(tx *gorm.DB) error {
+		if err := migration.runDown(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaMigration{}, migration.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revert migration %s: %w", migration.ID(), err)
+	}
+
+	m.logger.Info("Migration reverted",
+		zap.String("migration", migration.ID()),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+// print writes the SQL a migration would run to the dry-run writer.
+func (m *Migrator) print(ctx context.Context, direction string, migration Migration, sql string, fn MigrationFunc) error {
+	fmt.Fprintf(m.dryRun, "-- %s %s\n", direction, migration.ID())
+	if fn == nil {
+		_, err := fmt.Fprintln(m.dryRun, sql)
+		return err
+	}
+
+	db := m.db.Session(&gorm.Session{
+		DryRun:  true,
+		Context: ctx,
+		Logger:  sqlWriter{w: m.dryRun},
+	})
+	if err := fn(db); err != nil {
+		return fmt.Errorf("failed to print migration %s: %w", migration.ID(), err)
+	}
+	_, err := fmt.Fprintln(m.dryRun)
+	return err
+}
+
+// ensureTable creates schema_migrations unless running in dry-run mode.
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	if m.dryRun != nil {
+		return nil
+	}
+	if err := m.db.WithContext(ctx).AutoMigrate(&SchemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+/*
+run executes fn in its own transaction unless the migration opted out. Either
+way DB_STATEMENT_TIMEOUT is lifted: it protects request handlers, while a
+backfill or an index build may legitimately run for minutes.
+*/
+func (m *Migrator) run(ctx context.Context, migration Migration, fn func(tx *gorm.DB) error) error {
+	if migration.NoTransaction {
+		return m.withoutTimeout(ctx, fn)
+	}
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL statement_timeout = 0").Error; err != nil {
+			return fmt.Errorf("failed to disable statement timeout: %w", err)
+		}
+		return fn(tx)
+	})
+}
+
+/*
+withoutTimeout runs fn outside a transaction on a dedicated connection with
+statement_timeout disabled, restoring it before the connection goes back to
+the pool. fn gets a plain session on that connection: dbresolver would route
+each statement to any pooled connection, which may not have the setting.
+*/
+func (m *Migrator) withoutTimeout(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection for migration: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SET statement_timeout = 0"); err != nil {
+		return fmt.Errorf("failed to disable statement timeout: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "RESET statement_timeout"); err != nil {
+			m.logger.Error("Failed to restore statement timeout", zap.Error(err))
+		}
+	}()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: m.db.Logger})
+	if err != nil {
+		return err
+	}
+	return fn(db.WithContext(ctx))
+}
+
+/*
+withLock runs fn while holding a session-level Postgres advisory lock, so only
+one replica migrates at a time. The lock lives on a dedicated connection and is
+released when fn returns.
+*/
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			m.logger.Error("Failed to release migration lock", zap.Error(err))
+		}
+	}()
+
+	return fn()
+}
+
+func checkModified(statuses []MigrationStatus) error {
+	for _, s := range statuses {
+		if s.Modified {
+			return fmt.Errorf("migration %s: %w", s.Migration.ID(), ErrMigrationChecksum)
+		}
+	}
+	return nil
+}
+
+func sortStatuses(statuses []MigrationStatus) {
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Migration.Version < statuses[j].Migration.Version
+	})
+}
+
+// sqlWriter is a GORM logger that writes every traced statement to w.
+type sqlWriter struct {
+	w io.Writer
+}
+
+func (l sqlWriter) LogMode(gorm_logger.LogLevel) gorm_logger.Interface { return l }
+func (l sqlWriter) Info(context.Context, string, ...any)               {}
+func (l sqlWriter) Warn(context.Context, string, ...any)               {}
+func (l sqlWriter) Error(context.Context, string, ...any)              {}
+
+func (l sqlWriter) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	fmt.Fprintf(l.w, "%s;\n", sql)
+}
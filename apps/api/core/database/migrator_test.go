@@ -0,0 +1,160 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"pengi-med-saas/core/database"
+	"pengi-med-saas/testutil"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+// widgetMigrations are applied on top of the migrated test database. The
+// second one builds an index CONCURRENTLY, which fails inside a transaction.
+var widgetMigrations = fstest.MapFS{
+	"sql/90000000000001_create_widgets.up.sql":   {Data: []byte("CREATE TABLE widgets (id bigserial PRIMARY KEY, name text);")},
+	"sql/90000000000001_create_widgets.down.sql": {Data: []byte("DROP TABLE widgets;")},
+	"sql/90000000000002_index_widgets.up.sql":    {Data: []byte("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY widgets_name_idx ON widgets (name);")},
+	"sql/90000000000002_index_widgets.down.sql":  {Data: []byte("-- migrate:no-transaction\nDROP INDEX CONCURRENTLY widgets_name_idx;")},
+}
+
+// newMigrator returns a Migrator for fsys on a database whose only recorded
+// migrations are the ones it applies.
+func newMigrator(t *testing.T, db *gorm.DB, fsys fstest.MapFS) *database.Migrator {
+	t.Helper()
+	migrations, err := database.LoadSQLMigrations(fsys, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(db, zap.NewNop(), migrations...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator
+}
+
+func applied(t *testing.T, migrator *database.Migrator) []int64 {
+	t.Helper()
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int64
+	for _, s := range statuses {
+		if s.Applied {
+			versions = append(versions, s.Migration.Version)
+		}
+	}
+	return versions
+}
+
+func TestMigratorUpDownStatus(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	ctx := context.Background()
+	if err := db.Exec("DELETE FROM schema_migrations").Error; err != nil {
+		t.Fatal(err)
+	}
+	migrator := newMigrator(t, db, widgetMigrations)
+
+	if got := applied(t, migrator); len(got) != 0 {
+		t.Fatalf("applied before Up = %v", got)
+	}
+
+	ran, err := migrator.Up(ctx, 1)
+	if err != nil || len(ran) != 1 || ran[0].Version != 90000000000001 {
+		t.Fatalf("Up(1) = %v, %v; want the first migration", ran, err)
+	}
+	ran, err = migrator.Up(ctx, 0)
+	if err != nil || len(ran) != 1 || ran[0].Version != 90000000000002 {
+		t.Fatalf("Up(0) = %v, %v; want the no-transaction migration", ran, err)
+	}
+	if ran, err := migrator.Up(ctx, 0); err != nil || len(ran) != 0 {
+		t.Fatalf("second Up = %v, %v; want nothing to do", ran, err)
+	}
+	if got := applied(t, migrator); len(got) != 2 {
+		t.Fatalf("applied after Up = %v", got)
+	}
+	if pending, err := migrator.Pending(ctx); err != nil || len(pending) != 0 {
+		t.Fatalf("Pending = %v, %v", pending, err)
+	}
+
+	redone, err := migrator.Redo(ctx)
+	if err != nil || redone == nil || redone.Version != 90000000000002 {
+		t.Fatalf("Redo = %v, %v; want the latest migration", redone, err)
+	}
+
+	reverted, err := migrator.Down(ctx, 2)
+	if err != nil || len(reverted) != 2 || reverted[0].Version != 90000000000002 {
+		t.Fatalf("Down(2) = %v, %v; want both, newest first", reverted, err)
+	}
+	if db.Migrator().HasTable("widgets") {
+		t.Error("widgets still exists after Down")
+	}
+	if got := applied(t, migrator); len(got) != 0 {
+		t.Errorf("applied after Down = %v", got)
+	}
+}
+
+func TestMigratorDetectsModifiedMigrations(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	ctx := context.Background()
+	if err := db.Exec("DELETE FROM schema_migrations").Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newMigrator(t, db, widgetMigrations).Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := fstest.MapFS{}
+	for name, file := range widgetMigrations {
+		edited[name] = file
+	}
+	edited["sql/90000000000001_create_widgets.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE IF EXISTS widgets;")}
+	migrator := newMigrator(t, db, edited)
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[0].Modified || statuses[1].Modified {
+		t.Errorf("Modified = %v, %v; want only the edited migration", statuses[0].Modified, statuses[1].Modified)
+	}
+	if _, err := migrator.Up(ctx, 0); !errors.Is(err, database.ErrMigrationChecksum) {
+		t.Errorf("Up error = %v, want ErrMigrationChecksum", err)
+	}
+	if _, err := migrator.Down(ctx, 1); !errors.Is(err, database.ErrMigrationChecksum) {
+		t.Errorf("Down error = %v, want ErrMigrationChecksum", err)
+	}
+}
+
+func TestMigratorMissingMigrations(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	ctx := context.Background()
+	if err := db.Exec("DELETE FROM schema_migrations").Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newMigrator(t, db, widgetMigrations).Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := newMigrator(t, db, fstest.MapFS{
+		"sql/90000000000001_create_widgets.up.sql":   widgetMigrations["sql/90000000000001_create_widgets.up.sql"],
+		"sql/90000000000001_create_widgets.down.sql": widgetMigrations["sql/90000000000001_create_widgets.down.sql"],
+	})
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || !statuses[1].Missing || !statuses[1].Applied {
+		t.Fatalf("statuses = %+v, want the removed migration listed as missing", statuses)
+	}
+	if _, err := migrator.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "not present in source") {
+		t.Errorf("Down error = %v, want a missing migration error", err)
+	}
+}
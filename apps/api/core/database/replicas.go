@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"pengi-med-saas/core/logger"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// replicaLagQuery returns the replay lag in seconds, or 0 when the replica has
+// replayed everything it received (an idle primary otherwise looks lagging).
+const replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+type requestScopeKey struct{}
+
+// requestScope tracks, per request, whether reads must go to the primary.
+type requestScope struct {
+	wrote   atomic.Bool
+	primary atomic.Bool
+}
+
+// WithRequestScope returns a context that tracks writes, so reads issued after
+// a write in the same request are served by the primary.
+func WithRequestScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestScopeKey{}, &requestScope{})
+}
+
+// ForcePrimary makes every remaining query of the request use the primary.
+// It is a no-op for contexts without a request scope.
+func ForcePrimary(ctx context.Context) {
+	if scope, ok := ctx.Value(requestScopeKey{}).(*requestScope); ok {
+		scope.primary.Store(true)
+	}
+}
+
+// Primary returns a session whose queries always go to the primary.
+func Primary(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write).Session(&gorm.Session{})
+}
+
+// ReplicaMiddleware attaches a request scope to every request. Handlers must
+// query with db.WithContext(c.Request.Context()) for it to take effect.
+func ReplicaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithRequestScope(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// registerReplicaCallbacks wires read-after-write tracking into db.
+func registerReplicaCallbacks(db *gorm.DB) error {
+	markWrite := func(tx *gorm.DB) {
+		if scope, ok := tx.Statement.Context.Value(requestScopeKey{}).(*requestScope); ok && tx.Error == nil {
+			scope.wrote.Store(true)
+		}
+	}
+	// dbresolver picks the connection before every other callback, so reads
+	// that need the primary are re-resolved right before they execute.
+	routeRead := func(tx *gorm.DB) {
+		if scope, ok := tx.Statement.Context.Value(requestScopeKey{}).(*requestScope); ok {
+			if scope.wrote.Load() || scope.primary.Load() {
+				dbresolver.Write.ModifyStatement(tx.Statement)
+			}
+		}
+	}
+
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().After("gorm:create").Register("pengi:mark_write", markWrite),
+		callbacks.Update().After("gorm:update").Register("pengi:mark_write", markWrite),
+		callbacks.Delete().After("gorm:delete").Register("pengi:mark_write", markWrite),
+		callbacks.Raw().After("gorm:raw").Register("pengi:mark_write", markWrite),
+		callbacks.Query().Before("gorm:query").Register("pengi:route_read", routeRead),
+		callbacks.Row().Before("gorm:row").Register("pengi:route_read", routeRead),
+	)
+}
+
+/*
+setupReplicas registers the read replicas on db. Reads go to a healthy replica
+chosen round-robin; replicas that fail a health check or lag more than
+cfg.ReplicaMaxLag are skipped until they recover, and reads fall back to the
+primary when none is healthy.
+
+Health checks run until ctx is done or stop is called; either way the replica
+pools are closed. Callers that fail after setupReplicas succeeded must call
+stop, or every connection attempt leaks a checker and its pools.
+*/
+func setupReplicas(ctx context.Context, db *gorm.DB, cfg Config) (stop func(), err error) {
+	primary, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &replicaPolicy{primary: primary, maxLag: cfg.ReplicaMaxLag}
+	dialectors := make([]gorm.Dialector, 0, len(cfg.Replicas))
+	for i, dsn := range cfg.Replicas {
+		replicaCfg := cfg
+		replicaCfg.URL = dsn
+
+		pool, err := sql.Open("pgx", replicaCfg.DSN())
+		if err != nil {
+			policy.close()
+			return nil, fmt.Errorf("error opening replica %s: %w", replicaCfg, err)
+		}
+		pool.SetMaxOpenConns(cfg.MaxOpenConns)
+		pool.SetMaxIdleConns(cfg.MaxIdleConns)
+		pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+		pool.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+		r := &replica{name: replicaCfg.String(), pool: pool}
+		policy.replicas = append(policy.replicas, r)
+		if r.metrics, err = registerPoolMetrics(pool, fmt.Sprintf("replica_%d", i+1)); err != nil {
+			policy.close()
+			return nil, fmt.Errorf("error registering metrics for replica %s: %w", replicaCfg, err)
+		}
+
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: pool}))
+	}
+
+	if err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   policy,
+	})); err != nil {
+		policy.close()
+		return nil, fmt.Errorf("error registering replicas: %w", err)
+	}
+	if err := registerReplicaCallbacks(db); err != nil {
+		policy.close()
+		return nil, fmt.Errorf("error registering replica callbacks: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	policy.checkAll(ctx)
+	go func() {
+		defer close(done)
+		policy.run(ctx, cfg.ReplicaCheckInterval)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+type replica struct {
+	name    string
+	pool    *sql.DB
+	metrics metric.Registration
+	healthy atomic.Bool
+}
+
+// replicaPolicy is a dbresolver.Policy that only hands out healthy replicas.
+type replicaPolicy struct {
+	primary  *sql.DB
+	replicas []*replica
+	maxLag   time.Duration
+	next     atomic.Uint64
+}
+
+func (p *replicaPolicy) Resolve([]gorm.ConnPool) gorm.ConnPool {
+	n := len(p.replicas)
+	start := int(p.next.Add(1))
+	for i := 0; i < n; i++ {
+		if r := p.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return p.primary
+}
+
+func (p *replicaPolicy) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer p.close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkAll(ctx)
+		}
+	}
+}
+
+func (p *replicaPolicy) checkAll(ctx context.Context) {
+	for _, r := range p.replicas {
+		err := p.check(ctx, r)
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			logger.Info("Replica is healthy, routing reads to it", zap.String("replica", r.name))
+		} else {
+			logger.Warn("Replica removed from read pool", zap.String("replica", r.name), zap.Error(err))
+		}
+	}
+}
+
+func (p *replicaPolicy) check(ctx context.Context, r *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	var lagSeconds float64
+	if err := r.pool.QueryRowContext(ctx, replicaLagQuery).Scan(&lagSeconds); err != nil {
+		return err
+	}
+	if lag := time.Duration(lagSeconds * float64(time.Second)); p.maxLag > 0 && lag > p.maxLag {
+		return fmt.Errorf("replication lag %s exceeds %s", lag.Round(time.Millisecond), p.maxLag)
+	}
+	return nil
+}
+
+// close stops reporting the replica pools and closes them.
+func (p *replicaPolicy) close() {
+	for _, r := range p.replicas {
+		if r.metrics != nil {
+			r.metrics.Unregister()
+		}
+		r.pool.Close()
+	}
+}
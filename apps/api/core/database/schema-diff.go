@@ -0,0 +1,210 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gorm_logger "gorm.io/gorm/logger"
+)
+
+var (
+	createTablePattern       = regexp.MustCompile(`^CREATE TABLE ("[^"]+")`)
+	createIndexPattern       = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX (?:IF NOT EXISTS )?("[^"]+")`)
+	addConstraintPattern     = regexp.MustCompile(`^ALTER TABLE ("[^"]+") ADD CONSTRAINT ("[^"]+")`)
+	addColumnPattern         = regexp.MustCompile(`^ALTER TABLE ("[^"]+") ADD ("[^"]+")`)
+	columnCommentPattern     = regexp.MustCompile(`^COMMENT ON COLUMN`)
+	errSchemaDiffUnsupported = errors.New("schema diff only supports PostgreSQL")
+)
+
+// SchemaDiff holds the statements that bring the live schema in line with the
+// models, plus best-effort statements to revert them.
+type SchemaDiff struct {
+	Up   []string
+	Down []string
+}
+
+// Empty reports whether the live schema already matches the models.
+func (d SchemaDiff) Empty() bool {
+	return len(d.Up) == 0
+}
+
+/*
+DiffSchema compares the models with the live schema and returns the DDL needed
+to reconcile them.
+
+Additions and type changes come from GORM's own AutoMigrate logic: it runs
+against a connection that executes introspection queries but only records DDL.
+Columns present in the database but not in the models are dropped, which
+AutoMigrate never does. Renames can't be detected, so an added and a dropped
+column in the same table are flagged for review.
+
+The result is meant to be written to a migration file and reviewed, never
+applied blindly.
+*/
+func DiffSchema(db *gorm.DB, models ...any) (SchemaDiff, error) {
+	if db.Dialector.Name() != "postgres" {
+		return SchemaDiff{}, errSchemaDiffUnsupported
+	}
+
+	db = Primary(db)
+	sqlDB, err := db.DB()
+	if err != nil {
+		return SchemaDiff{}, fmt.Errorf("failed to get database handle: %w", err)
+	}
+
+	recorder := &recordingPool{db: sqlDB}
+	dryDB, err := gorm.Open(postgres.New(postgres.Config{Conn: recorder}), &gorm.Config{
+		NamingStrategy:       db.NamingStrategy,
+		Logger:               gorm_logger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return SchemaDiff{}, fmt.Errorf("failed to open recording connection: %w", err)
+	}
+
+	if err := dryDB.AutoMigrate(models...); err != nil {
+		return SchemaDiff{}, fmt.Errorf("failed to compute model changes: %w", err)
+	}
+
+	var diff SchemaDiff
+	added := make(map[string][]string) // table -> added columns
+	for _, stmt := range recorder.statements {
+		diff.Up = append(diff.Up, stmt)
+		diff.Down = append(diff.Down, revertStatement(stmt))
+		if m := addColumnPattern.FindStringSubmatch(stmt); m != nil {
+			added[m[1]] = append(added[m[1]], m[2])
+		}
+	}
+
+	dropped, err := droppedColumns(db, models...)
+	if err != nil {
+		return SchemaDiff{}, err
+	}
+	for _, d := range dropped {
+		if cols := added[d.table]; len(cols) > 0 {
+			diff.Up = append(diff.Up, fmt.Sprintf(
+				"-- REVIEW: %s drops %s and adds %s; if this is a rename, replace both with:\n-- ALTER TABLE %s RENAME COLUMN %s TO <new_name>",
+				d.table, d.column, strings.Join(cols, ", "), d.table, d.column,
+			))
+		}
+		diff.Up = append(diff.Up, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.table, d.column))
+		diff.Down = append(diff.Down, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", d.table, d.column, d.dataType))
+	}
+
+	// Down statements run in reverse order of their up counterparts.
+	for i, j := 0, len(diff.Down)-1; i < j; i, j = i+1, j-1 {
+		diff.Down[i], diff.Down[j] = diff.Down[j], diff.Down[i]
+	}
+
+	return diff, nil
+}
+
+// revertStatement returns the statement that undoes a DDL statement recorded
+// from AutoMigrate, or a review comment when there is no safe inverse.
+func revertStatement(stmt string) string {
+	if m := createTablePattern.FindStringSubmatch(stmt); m != nil {
+		return fmt.Sprintf("DROP TABLE IF EXISTS %s", m[1])
+	}
+	if m := createIndexPattern.FindStringSubmatch(stmt); m != nil {
+		return fmt.Sprintf("DROP INDEX IF EXISTS %s", m[1])
+	}
+	if m := addConstraintPattern.FindStringSubmatch(stmt); m != nil {
+		return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", m[1], m[2])
+	}
+	if m := addColumnPattern.FindStringSubmatch(stmt); m != nil {
+		return fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", m[1], m[2])
+	}
+	if columnCommentPattern.MatchString(stmt) {
+		return "-- column comment changes are not reverted"
+	}
+	return "-- REVIEW: no automatic revert for: " + strings.ReplaceAll(stmt, "\n", " ")
+}
+
+type droppedColumn struct {
+	table    string
+	column   string
+	dataType string
+}
+
+// droppedColumns lists columns of existing model tables that no model field maps to.
+func droppedColumns(db *gorm.DB, models ...any) ([]droppedColumn, error) {
+	var dropped []droppedColumn
+	migrator := db.Migrator()
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		if !migrator.HasTable(stmt.Schema.Table) {
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(stmt.Schema.Table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", stmt.Schema.Table, err)
+		}
+
+		for _, columnType := range columnTypes {
+			if stmt.Schema.LookUpField(columnType.Name()) != nil {
+				continue
+			}
+			dataType := columnType.DatabaseTypeName()
+			if length, ok := columnType.Length(); ok && length > 0 {
+				dataType = fmt.Sprintf("%s(%d)", dataType, length)
+			}
+			dropped = append(dropped, droppedColumn{
+				table:    stmt.Quote(stmt.Schema.Table),
+				column:   stmt.Quote(columnType.Name()),
+				dataType: dataType,
+			})
+		}
+	}
+
+	sort.SliceStable(dropped, func(i, j int) bool {
+		return dropped[i].table < dropped[j].table
+	})
+	return dropped, nil
+}
+
+/*
+recordingPool is a gorm.ConnPool that forwards queries to the real database
+but records every Exec instead of running it, so AutoMigrate can inspect the
+live schema without changing it.
+*/
+type recordingPool struct {
+	db         *sql.DB
+	statements []string
+}
+
+func (p *recordingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.db.PrepareContext(ctx, query)
+}
+
+func (p *recordingPool) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	if len(args) > 0 {
+		query = postgres.Dialector{}.Explain(query, args...)
+	}
+	p.statements = append(p.statements, query)
+	return recordedResult{}, nil
+}
+
+func (p *recordingPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, query, args...)
+}
+
+func (p *recordingPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.db.QueryRowContext(ctx, query, args...)
+}
+
+type recordedResult struct{}
+
+func (recordedResult) LastInsertId() (int64, error) { return 0, nil }
+func (recordedResult) RowsAffected() (int64, error) { return 0, nil }
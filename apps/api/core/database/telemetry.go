@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	instrumentationName = "pengi-med-saas/core/database"
+	spanInstanceKey     = "pengi:otel_span"
+)
+
+var tracer = otel.Tracer(instrumentationName)
+
+/*
+registerTracingCallbacks wraps every GORM operation in a client span named
+after the operation and table ("SELECT users"), with the SQL text. Raw and
+Row statements take the operation from the SQL itself.
+*/
+func registerTracingCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("pengi:start_span", startSpan("INSERT")),
+		callbacks.Create().After("gorm:create").Register("pengi:end_span", endSpan),
+		callbacks.Query().Before("gorm:query").Register("pengi:start_span", startSpan("SELECT")),
+		callbacks.Query().After("gorm:query").Register("pengi:end_span", endSpan),
+		callbacks.Update().Before("gorm:update").Register("pengi:start_span", startSpan("UPDATE")),
+		callbacks.Update().After("gorm:update").Register("pengi:end_span", endSpan),
+		callbacks.Delete().Before("gorm:delete").Register("pengi:start_span", startSpan("DELETE")),
+		callbacks.Delete().After("gorm:delete").Register("pengi:end_span", endSpan),
+		callbacks.Row().Before("gorm:row").Register("pengi:start_span", startSpan("")),
+		callbacks.Row().After("gorm:row").Register("pengi:end_span", endSpan),
+		callbacks.Raw().Before("gorm:raw").Register("pengi:start_span", startSpan("")),
+		callbacks.Raw().After("gorm:raw").Register("pengi:end_span", endSpan),
+	)
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		_, span := tracer.Start(tx.Statement.Context, "gorm",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		)
+		if !span.IsRecording() {
+			return
+		}
+		if operation != "" {
+			span.SetAttributes(semconv.DBOperationName(operation))
+		}
+		tx.InstanceSet(spanInstanceKey, span)
+	}
+}
+
+func endSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(spanInstanceKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	statement := tx.Statement.SQL.String()
+	operation := strings.ToUpper(strings.SplitN(strings.TrimSpace(statement), " ", 2)[0])
+	name := operation
+	if table := tx.Statement.Table; table != "" {
+		name += " " + table
+		span.SetAttributes(semconv.DBCollectionName(table))
+	}
+	span.SetName(name)
+	span.SetAttributes(
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(statement),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
+
+// registerPoolMetrics reports the sql.DB pool statistics on every collection
+// until the returned registration is unregistered.
+func registerPoolMetrics(pool *sql.DB, name string) (metric.Registration, error) {
+	meter := otel.Meter(instrumentationName)
+	connections, err := meter.Int64ObservableGauge("db.client.connection.count",
+		metric.WithDescription("Connections in the pool by state."))
+	if err != nil {
+		return nil, err
+	}
+	maxConnections, err := meter.Int64ObservableGauge("db.client.connection.max",
+		metric.WithDescription("Maximum number of open connections allowed."))
+	if err != nil {
+		return nil, err
+	}
+	waits, err := meter.Int64ObservableCounter("db.client.connection.waits",
+		metric.WithDescription("Times a query waited for a free connection."))
+	if err != nil {
+		return nil, err
+	}
+	waitTime, err := meter.Float64ObservableCounter("db.client.connection.wait_time",
+		metric.WithUnit("s"),
+		metric.WithDescription("Total time spent waiting for a free connection."))
+	if err != nil {
+		return nil, err
+	}
+
+	poolName := semconv.DBClientConnectionPoolName(name)
+	idle := metric.WithAttributes(poolName, semconv.DBClientConnectionStateIdle)
+	used := metric.WithAttributes(poolName, semconv.DBClientConnectionStateUsed)
+	byPool := metric.WithAttributes(poolName)
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stats := pool.Stats()
+		o.ObserveInt64(connections, int64(stats.Idle), idle)
+		o.ObserveInt64(connections, int64(stats.InUse), used)
+		o.ObserveInt64(maxConnections, int64(stats.MaxOpenConnections), byPool)
+		o.ObserveInt64(waits, stats.WaitCount, byPool)
+		o.ObserveFloat64(waitTime, stats.WaitDuration.Seconds(), byPool)
+		return nil
+	}, connections, maxConnections, waits, waitTime)
+}
@@ -1,33 +1,149 @@
 package envelope
 
 import (
+	"net/http"
+	"pengi-med-saas/core/database"
 	core_errors "pengi-med-saas/core/errors"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type Action func(r *gin.Context) Response
 
 func Handle(action Action) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		response := action(c)
-
-		// Translate response if translator is available
-		if val, exists := c.Get("translator"); exists {
-			if translate, ok := val.(func(string) string); ok {
-				response.Message = translate(response.Message)
-				if appErr, ok := response.Data.(core_errors.AppError); ok {
-					appErr.ErrorMessage = translate(appErr.ErrorCode)
-					response.Data = appErr
-				}
+		respond(c, action(c))
+	}
+}
+
+/*
+HandleTx is Handle wrapped in a unit of work: it opens a transaction bound to
+the request context, exposes it through database.FromContext, commits when the
+action returns a success Response and rolls back on error codes or panics.
+
+A nil db runs the action without a transaction, so routes can be exercised
+with in-memory repositories.
+*/
+func HandleTx(db *gorm.DB, action Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respond(c, runInTx(c, db, action))
+	}
+}
+
+func runInTx(c *gin.Context, db *gorm.DB, action Action) Response {
+	if db == nil {
+		return action(c)
+	}
+
+	tx := db.WithContext(c.Request.Context()).Begin()
+	if tx.Error != nil {
+		return ErrorResponse(http.StatusInternalServerError, "Error starting transaction", core_errors.ErrInternal)
+	}
+
+	committed := false
+	defer func() {
+		// Also runs while a panic unwinds, so the transaction never leaks.
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	c.Request = c.Request.WithContext(database.WithTx(c.Request.Context(), tx))
+	response := action(c)
+	if response.Code > 399 {
+		return response
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return ErrorResponse(http.StatusInternalServerError, "Error committing transaction", core_errors.ErrInternal)
+	}
+	committed = true
+	return response
+}
+
+// Abort responds like Handle and stops the handler chain; middleware use it
+// so their errors are translated and negotiated like any other.
+func Abort(c *gin.Context, response Response) {
+	respond(c, response)
+	c.Abort()
+}
+
+func respond(c *gin.Context, response Response) {
+	if response.Code == http.StatusNotModified {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	if response.err != nil {
+		if appErr, ok := response.Data.(core_errors.AppError); ok {
+			logAppError(requestLogger(c), response.Code, appErr, response.err)
+		}
+	}
+
+	// Translate response if translator is available
+	if translate, ok := translator(c); ok {
+		response.Message = translate(response.Message, response.Args)
+		if appErr, ok := response.Data.(core_errors.AppError); ok {
+			key := appErr.MessageKey
+			if key == "" {
+				key = appErr.ErrorCode
+			}
+			// Keep the default message when the key has no translation.
+			if translated := translate(key, appErr.Args); translated != key {
+				appErr.ErrorMessage = translated
 			}
+			appErr.Details = translateDetails(translate, appErr.Details)
+			response.Data = appErr
 		}
+	}
+
+	if response.Code >= http.StatusInternalServerError {
+		response = maskInternal(c, response)
+		// Lets clients quote the failure to support.
+		response.TraceID = traceID(c)
+	}
+
+	if response.Meta != nil {
+		setLinkHeader(c, *response.Meta)
+	}
 
-		if response.Code > 399 {
-			c.JSON(response.Code, response)
+	if response.Code > 399 {
+		if wantsProblem(c.GetHeader("Accept")) {
+			respondProblem(c, response)
 			return
 		}
-
 		c.JSON(response.Code, response)
+		return
+	}
+
+	c.JSON(response.Code, response)
+}
+
+// translator returns the request's translator set by the i18n middleware.
+func translator(c *gin.Context) (func(string, map[string]any) string, bool) {
+	val, exists := c.Get("translator")
+	if !exists {
+		return nil, false
+	}
+	translate, ok := val.(func(string, map[string]any) string)
+	return translate, ok
+}
+
+// translateDetails fills the message of each field error, which can refer to
+// the field as {field} and to the rule's parameters.
+func translateDetails(translate func(string, map[string]any) string, details []core_errors.FieldError) []core_errors.FieldError {
+	if details == nil {
+		return nil
+	}
+	translated := make([]core_errors.FieldError, len(details))
+	for i, detail := range details {
+		args := map[string]any{"field": detail.Field}
+		for key, value := range detail.Params {
+			args[key] = value
+		}
+		detail.Message = translate(detail.MessageKey, args)
+		translated[i] = detail
 	}
+	return translated
 }
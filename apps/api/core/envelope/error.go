@@ -0,0 +1,64 @@
+package envelope
+
+import (
+	"errors"
+	"net/http"
+	core_errors "pengi-med-saas/core/errors"
+	"pengi-med-saas/core/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+/*
+FromError turns an error returned by a service into a response. An AppError
+anywhere in the chain decides the status and what the client sees; any other
+error becomes ErrInternal. The full error, cause included, is only logged,
+with the request's logger once the response is sent:
+
+	companies, err := h.service.List(ctx, params)
+	if err != nil {
+		return envelope.FromError(err)
+	}
+*/
+func FromError(err error) Response {
+	var appErr core_errors.AppError
+	if !errors.As(err, &appErr) {
+		appErr = core_errors.ErrInternal.Wrap(err)
+	}
+
+	status := appErr.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	response := ErrorResponse(status, appErr.ErrorMessage, appErr)
+	response.err = err
+	return response
+}
+
+func logAppError(log *zap.Logger, status int, appErr core_errors.AppError, err error) {
+	fields := []zap.Field{
+		zap.String("code", appErr.ErrorCode),
+		zap.Int("status", status),
+		zap.Error(err),
+	}
+	switch appErr.Severity {
+	case core_errors.SeverityInfo:
+		log.Info("Request failed", fields...)
+	case core_errors.SeverityWarning:
+		log.Warn("Request failed", fields...)
+	default:
+		log.Error("Request failed", fields...)
+	}
+}
+
+// requestLogger is the request's logger, with the trace ID added when no
+// RequestLogger middleware set one.
+func requestLogger(c *gin.Context) *zap.Logger {
+	log := logger.FromContext(c.Request.Context())
+	if _, ok := c.Get("request_id"); !ok {
+		log = log.With(zap.String("request_id", traceID(c)))
+	}
+	return log
+}
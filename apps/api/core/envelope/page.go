@@ -0,0 +1,74 @@
+package envelope
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	core_errors "pengi-med-saas/core/errors"
+	"pengi-med-saas/core/query"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Meta describes the page a list response holds.
+type Meta struct {
+	Total      int64  `json:"total"`
+	PageSize   int    `json:"page_size"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Paginated returns a page of a list endpoint; respond adds its Link header.
+func Paginated[T any](page query.Page[T], message string) Response {
+	response := SuccessResponse(page.Items, message)
+	response.Meta = &Meta{
+		Total:      page.Total,
+		PageSize:   page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+	}
+	return response
+}
+
+// InvalidQuery answers a list request whose parameters query.Parse rejected.
+func InvalidQuery(err error) Response {
+	param := ""
+	var paramErr *query.ParamError
+	if errors.As(err, &paramErr) {
+		param = paramErr.Param
+	}
+	return ErrorResponse(http.StatusBadRequest, err.Error(), core_errors.ErrInvalidQuery.WithArgs(map[string]any{"param": param}))
+}
+
+/*
+setLinkHeader adds RFC 8288 first/prev/next links. Requests paging with
+offset get offset links; the rest follow the cursor.
+*/
+func setLinkHeader(c *gin.Context, meta Meta) {
+	link := func(rel string, set map[string]string) string {
+		values := c.Request.URL.Query()
+		values.Del("cursor")
+		values.Del("offset")
+		for key, value := range set {
+			values.Set(key, value)
+		}
+		u := url.URL{Path: c.Request.URL.Path, RawQuery: values.Encode()}
+		return "<" + u.String() + `>; rel="` + rel + `"`
+	}
+
+	links := []string{link("first", nil)}
+	if c.Request.URL.Query().Has("offset") {
+		if meta.Offset > 0 {
+			prev := max(meta.Offset-meta.PageSize, 0)
+			links = append(links, link("prev", map[string]string{"offset": strconv.Itoa(prev)}))
+		}
+		if meta.NextCursor != "" {
+			links = append(links, link("next", map[string]string{"offset": strconv.Itoa(meta.Offset + meta.PageSize)}))
+		}
+	} else if meta.NextCursor != "" {
+		links = append(links, link("next", map[string]string{"cursor": meta.NextCursor}))
+	}
+	c.Header("Link", strings.Join(links, ", "))
+}
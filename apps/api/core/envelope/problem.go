@@ -0,0 +1,114 @@
+package envelope
+
+import (
+	"net/http"
+	core_errors "pengi-med-saas/core/errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	problemContentType = "application/problem+json"
+
+	// DefaultProblemTypeBaseURI prefixes the error code in Problem.Type.
+	DefaultProblemTypeBaseURI = "urn:pengi-med-saas:error:"
+)
+
+var problemTypeBase atomic.Value
+
+// SetProblemTypeBaseURI points problem types at the published error docs,
+// e.g. "https://docs.example.com/errors/".
+func SetProblemTypeBaseURI(uri string) {
+	problemTypeBase.Store(uri)
+}
+
+func problemTypeBaseURI() string {
+	if uri, ok := problemTypeBase.Load().(string); ok && uri != "" {
+		return uri
+	}
+	return DefaultProblemTypeBaseURI
+}
+
+/*
+Problem is an RFC 7807 error document. Clients opt in with
+Accept: application/problem+json; everyone else keeps the Response shape.
+The error code and field details travel as extension members.
+*/
+type Problem struct {
+	Type     string                   `json:"type"`
+	Title    string                   `json:"title"`
+	Status   int                      `json:"status"`
+	Detail   string                   `json:"detail,omitempty"`
+	Instance string                   `json:"instance,omitempty"`
+	TraceID  string                   `json:"trace_id,omitempty"`
+	Code     string                   `json:"code,omitempty"`
+	Errors   []core_errors.FieldError `json:"errors,omitempty"`
+}
+
+// newProblem converts an already translated error Response.
+func newProblem(c *gin.Context, response Response) Problem {
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(response.Code),
+		Status:   response.Code,
+		Detail:   response.Message,
+		Instance: c.Request.URL.Path,
+		TraceID:  traceID(c),
+	}
+	if appErr, ok := response.Data.(core_errors.AppError); ok {
+		problem.Type = problemTypeBaseURI() + appErr.ErrorCode
+		problem.Title = appErr.ErrorMessage
+		problem.Code = appErr.ErrorCode
+		problem.Errors = appErr.Details
+	}
+	return problem
+}
+
+// traceID identifies the request in logs: the request ID when one was set,
+// otherwise a new one that is also returned in X-Request-ID.
+func traceID(c *gin.Context) string {
+	if id := c.GetString("request_id"); id != "" {
+		return id
+	}
+	if id := c.GetHeader("X-Request-ID"); id != "" {
+		return id
+	}
+	id := uuid.NewString()
+	c.Set("request_id", id)
+	c.Header("X-Request-ID", id)
+	return id
+}
+
+// wantsProblem reports whether the Accept header prefers problem+json to
+// plain JSON.
+func wantsProblem(accept string) bool {
+	problemQ, jsonQ := 0.0, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case problemContentType:
+			problemQ = max(problemQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return problemQ > 0 && problemQ >= jsonQ
+}
+
+func respondProblem(c *gin.Context, response Response) {
+	// gin's JSON renderer keeps a Content-Type that is already set.
+	c.Header("Content-Type", problemContentType)
+	c.JSON(response.Code, newProblem(c, response))
+}
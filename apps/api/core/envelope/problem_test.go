@@ -0,0 +1,123 @@
+package envelope
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	core_errors "pengi-med-saas/core/errors"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWantsProblem(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"text/html", false},
+		{"application/problem+json", true},
+		{"Application/Problem+JSON", true},
+		{"application/problem+json;q=0", false},
+		{"application/problem+json, application/json", true},
+		{"application/json, application/problem+json", true},
+		{"application/json, application/problem+json;q=0.9", false},
+		{"application/json;q=0.5, application/problem+json;q=0.8", true},
+		{"application/json;q=0.8, application/problem+json;q=0.8", true},
+		{"*/*, application/problem+json;q=0.1", true},
+		{"text/html, application/xhtml+xml, */*;q=0.8", false},
+		{"application/problem+json;charset=utf-8;q=0.7, application/json;q=0.6", true},
+		{"application/problem+json;q=abc", true},
+	}
+	for _, tt := range tests {
+		if got := wantsProblem(tt.accept); got != tt.want {
+			t.Errorf("wantsProblem(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+// TestErrorNegotiation checks that only clients asking for problem+json get
+// it, and everyone else keeps the Response envelope.
+func TestErrorNegotiation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/companies/:id", Handle(func(c *gin.Context) Response {
+		return ErrorResponse(http.StatusNotFound, "Company 7 not found", core_errors.ErrCompanyNotFound)
+	}))
+
+	tests := []struct {
+		accept  string
+		problem bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/json, application/problem+json;q=0.5", false},
+		{"application/problem+json", true},
+		{"application/json;q=0.5, application/problem+json", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/companies/7", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404", rec.Code)
+			}
+			contentType := rec.Header().Get("Content-Type")
+
+			if tt.problem {
+				if contentType != problemContentType {
+					t.Errorf("Content-Type = %q, want %q", contentType, problemContentType)
+				}
+				var problem Problem
+				if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+					t.Fatal(err)
+				}
+				want := Problem{
+					Type:     DefaultProblemTypeBaseURI + core_errors.ErrCompanyNotFound.ErrorCode,
+					Title:    core_errors.ErrCompanyNotFound.ErrorMessage,
+					Status:   http.StatusNotFound,
+					Detail:   "Company 7 not found",
+					Instance: "/companies/7",
+					TraceID:  problem.TraceID,
+					Code:     core_errors.ErrCompanyNotFound.ErrorCode,
+				}
+				if problem.TraceID == "" || problem.TraceID != rec.Header().Get("X-Request-ID") {
+					t.Errorf("trace_id = %q, X-Request-ID = %q", problem.TraceID, rec.Header().Get("X-Request-ID"))
+				}
+				if !reflect.DeepEqual(problem, want) {
+					t.Errorf("problem = %+v, want %+v", problem, want)
+				}
+				return
+			}
+
+			if contentType != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]any{
+				"code":    float64(http.StatusNotFound),
+				"message": "Company 7 not found",
+				"data": map[string]any{
+					"error_code":    core_errors.ErrCompanyNotFound.ErrorCode,
+					"error_message": core_errors.ErrCompanyNotFound.ErrorMessage,
+				},
+			}
+			if !reflect.DeepEqual(body, want) {
+				t.Errorf("body = %v, want %v", body, want)
+			}
+		})
+	}
+}
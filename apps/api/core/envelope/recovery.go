@@ -0,0 +1,115 @@
+package envelope
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	core_errors "pengi-med-saas/core/errors"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var productionMode atomic.Bool
+
+/*
+SetProductionMode hides the message of 5xx responses from clients: it is
+replaced by the translated ErrInternal message and the original only goes to
+the log, next to the trace ID the client receives.
+*/
+func SetProductionMode(enabled bool) {
+	productionMode.Store(enabled)
+}
+
+/*
+Recovery replaces gin's Recovery: a panic in a handler or in a later
+middleware is logged with its stack and answered as ErrInternal, translated
+and negotiated like any other error response.
+*/
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			log := requestLogger(c)
+			if brokenPipe(recovered) {
+				log.Warn("Client connection lost", zap.Any("error", recovered))
+				c.Abort()
+				return
+			}
+
+			log.Error("Panic recovered",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Any("panic", recovered),
+				zap.Stack("stack"),
+			)
+			if c.Writer.Written() {
+				// Part of the body is already out; there is nothing valid left to send.
+				c.Abort()
+				return
+			}
+			Abort(c, ErrorResponse(http.StatusInternalServerError, core_errors.ErrInternal.ErrorMessage, core_errors.ErrInternal))
+		}()
+		c.Next()
+	}
+}
+
+// brokenPipe reports whether the panic comes from writing to a client that
+// has gone away, which is not worth a stack trace.
+func brokenPipe(recovered any) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if !errors.As(opErr, &syscallErr) {
+		return false
+	}
+	message := strings.ToLower(syscallErr.Error())
+	return strings.Contains(message, "broken pipe") || strings.Contains(message, "connection reset by peer")
+}
+
+// maskInternal swaps the message of a 5xx response for the generic one when
+// running in production, logging what the client would otherwise have seen.
+func maskInternal(c *gin.Context, response Response) Response {
+	if !productionMode.Load() || response.Code < http.StatusInternalServerError {
+		return response
+	}
+
+	appErr, ok := response.Data.(core_errors.AppError)
+	if !ok {
+		appErr = core_errors.ErrInternal
+	}
+	if response.Message != appErr.ErrorMessage && response.Message != core_errors.ErrInternal.ErrorMessage {
+		requestLogger(c).Error("Internal error message masked",
+			zap.String("code", appErr.ErrorCode),
+			zap.Int("status", response.Code),
+			zap.String("message", response.Message),
+		)
+	}
+
+	response.Message = core_errors.ErrInternal.ErrorMessage
+	if translate, ok := translator(c); ok {
+		if translated := translate(core_errors.ErrInternal.MessageKey, nil); translated != core_errors.ErrInternal.MessageKey {
+			response.Message = translated
+		}
+	}
+	response.Args = nil
+	appErr.Details = nil
+	response.Data = appErr
+	return response
+}
@@ -0,0 +1,158 @@
+package envelope
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	core_errors "pengi-med-saas/core/errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorBody is the Response envelope of an error as clients decode it.
+type errorBody struct {
+	Code    int                  `json:"code"`
+	Message string               `json:"message"`
+	Data    core_errors.AppError `json:"data"`
+	TraceID string               `json:"trace_id"`
+}
+
+func serve(t *testing.T, router *gin.Engine, header http.Header) (*httptest.ResponseRecorder, errorBody) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var body errorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", rec.Body, err)
+	}
+	return rec, body
+}
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Recovery())
+	router.GET("/", func(c *gin.Context) { panic("nil map") })
+
+	t.Run("new trace ID", func(t *testing.T) {
+		rec, body := serve(t, router, nil)
+		if rec.Code != http.StatusInternalServerError || body.Code != http.StatusInternalServerError {
+			t.Fatalf("status = %d, code = %d, want 500", rec.Code, body.Code)
+		}
+		if body.Data.ErrorCode != core_errors.ErrInternal.ErrorCode || body.Message != core_errors.ErrInternal.ErrorMessage {
+			t.Errorf("body = %+v, want ErrInternal", body)
+		}
+		if body.TraceID == "" || body.TraceID != rec.Header().Get("X-Request-ID") {
+			t.Errorf("trace_id = %q, X-Request-ID = %q", body.TraceID, rec.Header().Get("X-Request-ID"))
+		}
+	})
+
+	t.Run("client request ID", func(t *testing.T) {
+		_, body := serve(t, router, http.Header{"X-Request-Id": {"req-42"}})
+		if body.TraceID != "req-42" {
+			t.Errorf("trace_id = %q, want the client's req-42", body.TraceID)
+		}
+	})
+
+	t.Run("after the body started", func(t *testing.T) {
+		router := gin.New()
+		router.Use(Recovery())
+		router.GET("/", func(c *gin.Context) {
+			c.String(http.StatusOK, "partial")
+			panic("late")
+		})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+			t.Errorf("got %d %q, want the partial 200 left untouched", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("abort handler", func(t *testing.T) {
+		router := gin.New()
+		router.Use(Recovery())
+		router.GET("/", func(c *gin.Context) { panic(http.ErrAbortHandler) })
+		defer func() {
+			if recovered := recover(); recovered != http.ErrAbortHandler {
+				t.Errorf("recovered %v, want http.ErrAbortHandler re-panicked", recovered)
+			}
+		}()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestMaskInternal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { SetProductionMode(false) })
+
+	const leaked = `Error loading companies: pq: relation "companies" does not exist`
+	newRouter := func(translate bool) *gin.Engine {
+		router := gin.New()
+		if translate {
+			router.Use(func(c *gin.Context) {
+				c.Set("translator", func(key string, args map[string]any) string {
+					if key == core_errors.ErrInternal.MessageKey {
+						return "Error interno del servidor."
+					}
+					return key
+				})
+			})
+		}
+		router.GET("/", Handle(func(c *gin.Context) Response {
+			return ErrorResponse(http.StatusInternalServerError, leaked, core_errors.ErrInternal)
+		}))
+		router.GET("/conflict", Handle(func(c *gin.Context) Response {
+			return ErrorResponse(http.StatusConflict, "Company already exists", core_errors.ErrInternal)
+		}))
+		return router
+	}
+
+	tests := []struct {
+		name       string
+		production bool
+		translate  bool
+		want       string
+	}{
+		{"development", false, false, leaked},
+		{"production", true, false, core_errors.ErrInternal.ErrorMessage},
+		{"production translated", true, true, "Error interno del servidor."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetProductionMode(tt.production)
+			router := newRouter(tt.translate)
+
+			rec, body := serve(t, router, nil)
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want 500", rec.Code)
+			}
+			if body.Message != tt.want {
+				t.Errorf("message = %q, want %q", body.Message, tt.want)
+			}
+			if body.Data.ErrorCode != core_errors.ErrInternal.ErrorCode {
+				t.Errorf("error_code = %q, want %q", body.Data.ErrorCode, core_errors.ErrInternal.ErrorCode)
+			}
+			if body.TraceID == "" {
+				t.Error("trace_id is empty")
+			}
+
+			// Below 500 the message is the client's to see.
+			req := httptest.NewRequest(http.MethodGet, "/conflict", nil)
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			var conflict errorBody
+			if err := json.Unmarshal(rec.Body.Bytes(), &conflict); err != nil {
+				t.Fatal(err)
+			}
+			if conflict.Message != "Company already exists" || conflict.TraceID != "" {
+				t.Errorf("409 body = %+v, want it unmasked and without trace_id", conflict)
+			}
+		})
+	}
+}
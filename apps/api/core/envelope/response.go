@@ -11,6 +11,13 @@ type Response struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Data    any    `json:"data,omitempty"`
+	Meta    *Meta  `json:"meta,omitempty"`
+	// TraceID is only sent on 5xx responses, to match them with the logs.
+	TraceID string `json:"trace_id,omitempty"`
+	// Args fill the placeholders of the translated Message.
+	Args map[string]any `json:"-"`
+	// err is the error FromError built the response from, logged by respond.
+	err error
 }
 
 func New(code int, message string, data interface{}) Response {
@@ -33,6 +40,12 @@ func SuccessResponse(data any, message string) Response {
 	}
 }
 
+// NotModified answers a conditional request whose ETag still matches; it is
+// sent without a body.
+func NotModified() Response {
+	return Response{Code: http.StatusNotModified}
+}
+
 func ErrorResponse(code int, message string, data core_errors.AppError) Response {
 	return Response{
 		Code:    code,
@@ -41,6 +54,12 @@ func ErrorResponse(code int, message string, data core_errors.AppError) Response
 	}
 }
 
+// WithArgs sets the arguments the translated message is formatted with.
+func (r Response) WithArgs(args map[string]any) Response {
+	r.Args = args
+	return r
+}
+
 func (r Response) Unwrap() error {
 	if r.Code > 399 {
 		if reflect.TypeOf(r.Data) != reflect.TypeFor[core_errors.AppError]() {
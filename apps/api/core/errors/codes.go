@@ -1,21 +1,30 @@
 package core_errors
 
+import "net/http"
+
 var (
-	ErrInternal AppError = NewAppError("E-INT-001", "Internal server error.")
+	ErrInternal AppError = Register("E-INT-001", http.StatusInternalServerError, SeverityError, "Internal server error.")
+
+	ErrInvalidQuery AppError = Register("E-QRY-001", http.StatusBadRequest, SeverityInfo, "Invalid query parameter: {param}.")
 
-	ErrMessagesNotFound AppError = NewAppError("E-MES-001", "Messages not found.")
+	ErrMessagesNotFound      AppError = Register("E-MES-001", http.StatusNotFound, SeverityInfo, "Messages not found.")
+	ErrMessageNotFound       AppError = Register("E-MES-002", http.StatusNotFound, SeverityInfo, "Message not found.")
+	ErrMessageInvalidRequest AppError = Register("E-MES-003", http.StatusBadRequest, SeverityInfo, "Invalid message request.")
+	ErrMessageAlreadyExists  AppError = Register("E-MES-004", http.StatusConflict, SeverityInfo, "A message with this key and language already exists.")
+	ErrMessageImportError    AppError = Register("E-MES-005", http.StatusUnprocessableEntity, SeverityWarning, "Error importing messages.")
 
-	ErrCompanyNotFound AppError = NewAppError("E-COMP-001", "Company not found.")
+	ErrCompanyNotFound AppError = Register("E-COMP-001", http.StatusNotFound, SeverityInfo, "Company not found.")
 
-	ErrTenantNotFound AppError = NewAppError("E-TEN-001", "Tenant not found.")
+	ErrTenantNotFound AppError = Register("E-TEN-001", http.StatusNotFound, SeverityInfo, "Tenant not found.")
 
-	ErrUserNotFound AppError = NewAppError("E-USR-001", "User not found.")
+	ErrUserNotFound AppError = Register("E-USR-001", http.StatusNotFound, SeverityInfo, "User not found.")
 
 	// Auth Errors
-	ErrAuthInvalidRequest      AppError = NewAppError("E-AUTH-001", "Invalid authentication request.")
-	ErrAuthUserCreateError     AppError = NewAppError("E-AUTH-002", "Error creating user.")
-	ErrAuthInvalidCredentials  AppError = NewAppError("E-AUTH-003", "Invalid credentials.")
-	ErrAuthTokenGenerateError  AppError = NewAppError("E-AUTH-004", "Error generating token.")
-	ErrAuthInvalidRefreshToken AppError = NewAppError("E-AUTH-005", "Invalid refresh token.")
-	ErrAuthUserInvalidID       AppError = NewAppError("E-AUTH-006", "Invalid user ID.")
+	ErrAuthInvalidRequest      AppError = Register("E-AUTH-001", http.StatusBadRequest, SeverityInfo, "Invalid authentication request.")
+	ErrAuthUserCreateError     AppError = Register("E-AUTH-002", http.StatusInternalServerError, SeverityError, "Error creating user.")
+	ErrAuthInvalidCredentials  AppError = Register("E-AUTH-003", http.StatusUnauthorized, SeverityWarning, "Invalid credentials.")
+	ErrAuthTokenGenerateError  AppError = Register("E-AUTH-004", http.StatusInternalServerError, SeverityError, "Error generating token.")
+	ErrAuthInvalidRefreshToken AppError = Register("E-AUTH-005", http.StatusBadRequest, SeverityWarning, "Invalid refresh token.") // not 401: the web client's refresh flow relies on 400
+	ErrAuthUserInvalidID       AppError = Register("E-AUTH-006", http.StatusBadRequest, SeverityInfo, "Invalid user ID.")
+	ErrAuthForbidden           AppError = Register("E-AUTH-007", http.StatusForbidden, SeverityWarning, "You don't have permission to perform this action.")
 )
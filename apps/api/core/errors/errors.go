@@ -1,13 +1,91 @@
 package core_errors
 
+import "net/http"
+
+/*
+AppError is an error clients may see: a stable code, a default message and,
+for registered errors, the HTTP status, i18n key and severity to report it
+with. It is also a Go error, so services can return it, wrapping the internal
+cause, and handlers can match it with errors.Is/As:
+
+	return core_errors.ErrCompanyNotFound.Wrap(err)
+	...
+	errors.Is(err, core_errors.ErrCompanyNotFound) // true
+*/
 type AppError struct {
 	ErrorCode    string `json:"error_code"`
 	ErrorMessage string `json:"error_message"`
+	// Details lists the request fields that failed validation.
+	Details []FieldError `json:"details,omitempty"`
+	// Args fill the placeholders of the translated message, e.g. {field}.
+	Args map[string]any `json:"-"`
+
+	Status     int      `json:"-"`
+	MessageKey string   `json:"-"`
+	Severity   Severity `json:"-"`
+
+	// cause is logged by envelope.FromError and never sent to clients.
+	cause error
+}
+
+/*
+FieldError is one failed validation rule. Message is the translation of
+MessageKey, formatted with Params plus {field}; it holds the key until the
+response is translated.
+*/
+type FieldError struct {
+	Field      string         `json:"field"`
+	Rule       string         `json:"rule"`
+	MessageKey string         `json:"message_key"`
+	Params     map[string]any `json:"params,omitempty"`
+	Message    string         `json:"message"`
 }
 
+// NewAppError creates an unregistered error, reported as a 500 unless the
+// handler says otherwise. Prefer Register for errors shared across handlers.
 func NewAppError(code string, message string) AppError {
 	return AppError{
 		ErrorCode:    code,
 		ErrorMessage: message,
+		Status:       http.StatusInternalServerError,
+		MessageKey:   code,
+		Severity:     SeverityError,
 	}
 }
+
+func (e AppError) Error() string {
+	if e.cause != nil {
+		return e.ErrorCode + ": " + e.ErrorMessage + ": " + e.cause.Error()
+	}
+	return e.ErrorCode + ": " + e.ErrorMessage
+}
+
+// Is matches AppErrors by code, so copies carrying a cause, args or details
+// still match the registered value.
+func (e AppError) Is(target error) bool {
+	t, ok := target.(AppError)
+	return ok && t.ErrorCode == e.ErrorCode
+}
+
+func (e AppError) Unwrap() error {
+	return e.cause
+}
+
+// Wrap returns a copy of the error that records cause for logging.
+func (e AppError) Wrap(cause error) AppError {
+	e.cause = cause
+	return e
+}
+
+// WithArgs returns a copy of the error whose message is formatted with args,
+// leaving the shared error value untouched.
+func (e AppError) WithArgs(args map[string]any) AppError {
+	e.Args = args
+	return e
+}
+
+// WithDetails returns a copy of the error carrying field-level details.
+func (e AppError) WithDetails(details []FieldError) AppError {
+	e.Details = details
+	return e
+}
@@ -0,0 +1,80 @@
+package core_errors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Severity decides how loudly an error is logged when it reaches a client.
+type Severity int
+
+const (
+	// SeverityInfo is an expected client mistake: bad input, missing record.
+	SeverityInfo Severity = iota
+	// SeverityWarning deserves attention but is not a bug, e.g. failed logins.
+	SeverityWarning
+	// SeverityError is a server-side failure.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "error"
+}
+
+var (
+	registry      = make(map[string]AppError)
+	registryMutex sync.RWMutex
+)
+
+/*
+Register declares an error code with the HTTP status it is reported with and
+its severity. The code doubles as the i18n key of the message, so every
+registered code needs an entry in messages_<lang>.json. Registering a code
+twice panics, as it is a programming error.
+*/
+func Register(code string, status int, severity Severity, message string) AppError {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, exists := registry[code]; exists {
+		panic(fmt.Sprintf("core_errors: %s registered twice", code))
+	}
+	err := AppError{
+		ErrorCode:    code,
+		ErrorMessage: message,
+		Status:       status,
+		MessageKey:   code,
+		Severity:     severity,
+	}
+	registry[code] = err
+	return err
+}
+
+// Lookup returns the registered error with code.
+func Lookup(code string) (AppError, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	err, ok := registry[code]
+	return err, ok
+}
+
+// All returns every registered error sorted by code.
+func All() []AppError {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	all := make([]AppError, 0, len(registry))
+	for _, err := range registry {
+		all = append(all, err)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ErrorCode < all[j].ErrorCode })
+	return all
+}
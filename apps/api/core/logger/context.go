@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// WithLogger returns a copy of ctx that carries l.
+func WithLogger(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+/*
+FromContext returns the request logger stored by RequestLogger, already
+carrying the request ID, route, tenant and user, so handlers log with:
+
+	logger.FromContext(c.Request.Context()).Info("Users fetched", zap.Int("count", n))
+
+Outside a request it returns the global logger, or a no-op one before Init.
+*/
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+			return l
+		}
+	}
+	if Log == nil {
+		return zap.NewNop()
+	}
+	return Log
+}
+
+// With adds fields to the logger in ctx, e.g. once the tenant is resolved.
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(fields...))
+}
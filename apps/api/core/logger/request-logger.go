@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// maxRequestIDLength bounds client supplied IDs before they reach the logs.
+	maxRequestIDLength = 128
+)
+
+/*
+RequestLogger replaces gin's text access log. It takes the X-Request-ID sent
+by the client or a proxy (or generates one), echoes it in the response and
+stores a child logger with the request ID and route in the request context,
+see FromContext. Once the request is done it writes one structured access log
+entry with status and latency, at error level for 5xx and warn for 4xx.
+*/
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		fields := []zap.Field{zap.String("request_id", id), zap.String("route", route)}
+		// Set when tracing is enabled, to jump from a log line to its trace.
+		if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+			fields = append(fields, zap.String("trace_id", span.TraceID().String()))
+		}
+		requestLogger := FromContext(c.Request.Context()).With(fields...)
+		c.Request = c.Request.WithContext(WithLogger(c.Request.Context(), requestLogger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		fields = []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.Int("size", c.Writer.Size()),
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate).String(); errs != "" {
+			fields = append(fields, zap.String("errors", errs))
+		}
+
+		// Tenant and user were added by later middleware.
+		access := FromContext(c.Request.Context())
+		switch {
+		case status >= 500:
+			access.Error("Request completed", fields...)
+		case status >= 400:
+			access.Warn("Request completed", fields...)
+		default:
+			access.Info("Request completed", fields...)
+		}
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || r == ' ' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+package query
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// schemas caches parsed models for column lookups on items.
+var schemas sync.Map
+
+// A cursor is the sort column values of the last item of a page, so the next
+// page starts right after it whatever was inserted or deleted meanwhile. NULL
+// is encoded as JSON null, so it can't be mistaken for an empty string.
+func encodeCursor(values []*string) string {
+	data, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) ([]*string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var values []*string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	if values == nil {
+		return nil, errors.New("query: empty cursor")
+	}
+	return values, nil
+}
+
+// columnValue returns the value of the field mapped to column in item.
+func columnValue(item any, column string) (any, error) {
+	s, err := schema.Parse(item, &schemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+	field := s.LookUpField(column)
+	if field == nil {
+		return nil, fmt.Errorf("query: %s has no column %q", s.Name, column)
+	}
+	value, zero := field.ValueOf(context.Background(), reflect.Indirect(reflect.ValueOf(item)))
+	if zero && field.FieldType.Kind() == reflect.Pointer {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func cursorFor(item any, sort []Sort) (string, error) {
+	values := make([]*string, len(sort))
+	for i, s := range sort {
+		value, err := columnValue(item, s.Column)
+		if err != nil {
+			return "", err
+		}
+		values[i] = formatNullable(value)
+	}
+	return encodeCursor(values), nil
+}
+
+// formatNullable is formatValue with nil for NULL.
+func formatNullable(value any) *string {
+	if deref(value) == nil {
+		return nil
+	}
+	s := formatValue(value)
+	return &s
+}
+
+func formatValue(value any) string {
+	value = deref(value)
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
+
+func deref(value any) any {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+/*
+compareNullable is compare with nil params for NULL. NULL sorts after every
+value, as in Postgres, where ascending order puts NULLs last and descending
+order first.
+*/
+func compareNullable(value any, param *string) (int, error) {
+	switch {
+	case deref(value) == nil && param == nil:
+		return 0, nil
+	case param == nil:
+		return -1, nil
+	}
+	return compare(value, *param)
+}
+
+/*
+compare orders a field value against a parameter, parsing the parameter as
+the field's type. It returns -1, 0 or 1; nil sorts after everything.
+*/
+func compare(value any, param string) (int, error) {
+	value = deref(value)
+	switch v := value.(type) {
+	case nil:
+		return 1, nil
+	case string:
+		return strings.Compare(v, param), nil
+	case time.Time:
+		t, err := time.Parse(time.RFC3339Nano, param)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, param)
+		}
+		if err != nil {
+			return 0, err
+		}
+		return v.Compare(t), nil
+	case bool:
+		b, err := strconv.ParseBool(param)
+		if err != nil {
+			return 0, err
+		}
+		return compareOrdered(boolInt(v), boolInt(b)), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.CanInt():
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareOrdered(rv.Int(), n), nil
+	case rv.CanUint():
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareOrdered(rv.Uint(), n), nil
+	case rv.CanFloat():
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareOrdered(rv.Float(), n), nil
+	}
+	return strings.Compare(fmt.Sprint(value), param), nil
+}
+
+func compareOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
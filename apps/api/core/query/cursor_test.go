@@ -0,0 +1,86 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	ID        uint
+	Name      string
+	Score     *int
+	CreatedAt time.Time
+}
+
+func score(n int) *int { return &n }
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := [][]*string{
+		{ptr("1")},
+		{ptr(""), ptr("2")},
+		{nil, ptr("3")},
+		{ptr("with \"quotes\", commas"), nil},
+	}
+	for _, values := range tests {
+		got, err := decodeCursor(encodeCursor(values))
+		if err != nil {
+			t.Fatalf("decodeCursor(encodeCursor(%v)) error: %v", values, err)
+		}
+		if !reflect.DeepEqual(got, values) {
+			t.Errorf("decodeCursor(encodeCursor(%v)) = %v", values, got)
+		}
+	}
+}
+
+func TestCursorFor(t *testing.T) {
+	created := time.Date(2026, 3, 5, 10, 0, 0, 123, time.FixedZone("ECT", -5*3600))
+	sort := []Sort{{Column: "score"}, {Column: "created_at", Desc: true}, {Column: "name"}, {Column: "id"}}
+
+	tests := []struct {
+		item testItem
+		want []*string
+	}{
+		{testItem{ID: 7, Name: "Ana", Score: score(10), CreatedAt: created}, []*string{ptr("10"), ptr("2026-03-05T10:00:00.000000123-05:00"), ptr("Ana"), ptr("7")}},
+		{testItem{ID: 8, Score: nil, CreatedAt: created}, []*string{nil, ptr("2026-03-05T10:00:00.000000123-05:00"), ptr(""), ptr("8")}},
+	}
+	for _, tt := range tests {
+		cursor, err := cursorFor(tt.item, sort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeCursor(cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("cursorFor(%+v) = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+}
+
+func TestCompareNullable(t *testing.T) {
+	tests := []struct {
+		value any
+		param *string
+		want  int
+	}{
+		{score(1), ptr("2"), -1},
+		{score(2), ptr("2"), 0},
+		{score(3), ptr("2"), 1},
+		{(*int)(nil), ptr("2"), 1},
+		{score(2), nil, -1},
+		{(*int)(nil), nil, 0},
+		{"b", ptr("a"), 1},
+		{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), ptr("2026-01-02"), -1},
+	}
+	for _, tt := range tests {
+		got, err := compareNullable(tt.value, tt.param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("compareNullable(%v, %v) = %d, want %d", deref(tt.value), tt.param, got, tt.want)
+		}
+	}
+}
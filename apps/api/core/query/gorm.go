@@ -0,0 +1,142 @@
+package query
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+/*
+Find runs a list query: it counts the rows matching the filters, then loads
+one page in the requested order. db may already carry scopes such as a tenant
+condition or preloads; they apply to both queries.
+*/
+func Find[T any](db *gorm.DB, params Params) (Page[T], error) {
+	page := Page[T]{Items: []T{}, Limit: params.Limit, Offset: params.Offset}
+	// Both queries below start from db, so it must not accumulate their clauses.
+	db = db.Session(&gorm.Session{})
+
+	if err := db.Model(new(T)).Scopes(filterScope(params.Filters)).Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	query := db.Scopes(filterScope(params.Filters), sortScope(params.Sort))
+	if params.Cursor != nil {
+		query = query.Scopes(afterScope(params.Sort, params.Cursor))
+	} else if params.Offset > 0 {
+		query = query.Offset(params.Offset)
+	}
+
+	// One extra row tells whether there is a next page.
+	if err := query.Limit(params.Limit + 1).Find(&page.Items).Error; err != nil {
+		return page, err
+	}
+	return finish(page, params)
+}
+
+// finish trims the extra row and sets the cursor of the next page.
+func finish[T any](page Page[T], params Params) (Page[T], error) {
+	if len(page.Items) <= params.Limit {
+		return page, nil
+	}
+	page.Items = page.Items[:params.Limit]
+	cursor, err := cursorFor(page.Items[len(page.Items)-1], params.Sort)
+	if err != nil {
+		return page, err
+	}
+	page.NextCursor = cursor
+	return page, nil
+}
+
+func filterScope(filters []Filter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, f := range filters {
+			column := db.Statement.Quote(f.Column)
+			switch f.Operator {
+			case Eq:
+				db = db.Where(column+" = ?", f.Values[0])
+			case In:
+				db = db.Where(column+" IN ?", f.Values)
+			case Like:
+				db = db.Where(column+" ILIKE ?", "%"+escapeLike(f.Values[0])+"%")
+			case Gte:
+				db = db.Where(column+" >= ?", f.Values[0])
+			}
+		}
+		return db
+	}
+}
+
+func sortScope(sort []Sort) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, s := range sort {
+			order := db.Statement.Quote(s.Column)
+			if s.Desc {
+				order += " DESC"
+			}
+			db = db.Order(order)
+		}
+		return db
+	}
+}
+
+/*
+afterScope keeps the rows that come after the cursor in the sort order:
+
+	(a > ? OR a IS NULL) OR (a = ? AND b < ?) OR (a = ? AND b = ? AND id > ?)
+
+for sort=a,-b. NULLs sort as Postgres orders them by default, last going up
+and first going down, so a nullable column pages through its NULLs too.
+*/
+func afterScope(sort []Sort, cursor []*string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		var (
+			alternatives []string
+			args         []any
+		)
+		for i, s := range sort {
+			after, afterArgs := afterTerm(db.Statement.Quote(s.Column), s.Desc, cursor[i])
+			if after == "" {
+				continue
+			}
+			var terms []string
+			for j := 0; j < i; j++ {
+				equal, equalArgs := equalTerm(db.Statement.Quote(sort[j].Column), cursor[j])
+				terms = append(terms, equal)
+				args = append(args, equalArgs...)
+			}
+			terms = append(terms, after)
+			args = append(args, afterArgs...)
+			alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+		}
+		if len(alternatives) == 0 {
+			return db.Where("false")
+		}
+		return db.Where(strings.Join(alternatives, " OR "), args...)
+	}
+}
+
+// afterTerm matches the values of column that sort strictly after value, or
+// returns "" when none can.
+func afterTerm(column string, desc bool, value *string) (string, []any) {
+	switch {
+	case value == nil && desc:
+		return column + " IS NOT NULL", nil
+	case value == nil:
+		return "", nil
+	case desc:
+		return column + " < ?", []any{*value}
+	}
+	return "(" + column + " > ? OR " + column + " IS NULL)", []any{*value}
+}
+
+func equalTerm(column string, value *string) (string, []any) {
+	if value == nil {
+		return column + " IS NULL", nil
+	}
+	return column + " = ?", []any{*value}
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRun returns a session that builds SQL without a database.
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestAfterScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		sort   []Sort
+		cursor []*string
+		sql    string
+		vars   []any
+	}{
+		{
+			"ascending",
+			[]Sort{{Column: "name"}, {Column: "id"}},
+			[]*string{ptr("Ana"), ptr("3")},
+			`SELECT * FROM "test_items" WHERE (("name" > $1 OR "name" IS NULL)) OR ("name" = $2 AND ("id" > $3 OR "id" IS NULL))`,
+			[]any{"Ana", "Ana", "3"},
+		},
+		{
+			"descending",
+			[]Sort{{Column: "score", Desc: true}, {Column: "id"}},
+			[]*string{ptr("10"), ptr("3")},
+			`SELECT * FROM "test_items" WHERE ("score" < $1) OR ("score" = $2 AND ("id" > $3 OR "id" IS NULL))`,
+			[]any{"10", "10", "3"},
+		},
+		{
+			"ascending from NULL",
+			[]Sort{{Column: "score"}, {Column: "id"}},
+			[]*string{nil, ptr("3")},
+			`SELECT * FROM "test_items" WHERE ("score" IS NULL AND ("id" > $1 OR "id" IS NULL))`,
+			[]any{"3"},
+		},
+		{
+			"descending from NULL",
+			[]Sort{{Column: "score", Desc: true}, {Column: "id"}},
+			[]*string{nil, ptr("3")},
+			`SELECT * FROM "test_items" WHERE ("score" IS NOT NULL) OR ("score" IS NULL AND ("id" > $1 OR "id" IS NULL))`,
+			[]any{"3"},
+		},
+		{
+			"nothing after",
+			[]Sort{{Column: "score"}},
+			[]*string{nil},
+			`SELECT * FROM "test_items" WHERE false`,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := dryRun(t).Scopes(afterScope(tt.sort, tt.cursor)).Find(&[]testItem{}).Statement
+			if got := stmt.SQL.String(); got != tt.sql {
+				t.Errorf("SQL =\n%s\nwant\n%s", got, tt.sql)
+			}
+			if (len(stmt.Vars) > 0 || len(tt.vars) > 0) && !reflect.DeepEqual(stmt.Vars, tt.vars) {
+				t.Errorf("vars = %v, want %v", stmt.Vars, tt.vars)
+			}
+		})
+	}
+}
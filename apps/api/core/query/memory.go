@@ -0,0 +1,130 @@
+package query
+
+import (
+	"slices"
+	"strings"
+)
+
+/*
+Apply is Find for in-memory repositories: it filters, sorts and pages items
+with the same semantics, so tests see what Postgres would return.
+*/
+func Apply[T any](items []T, params Params) (Page[T], error) {
+	page := Page[T]{Items: []T{}, Limit: params.Limit, Offset: params.Offset}
+
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		ok, err := matches(item, params.Filters)
+		if err != nil {
+			return page, err
+		}
+		if ok {
+			matched = append(matched, item)
+		}
+	}
+	page.Total = int64(len(matched))
+
+	var sortErr error
+	slices.SortStableFunc(matched, func(a, b T) int {
+		cmp, err := compareItems(a, b, params.Sort)
+		if err != nil {
+			sortErr = err
+		}
+		return cmp
+	})
+	if sortErr != nil {
+		return page, sortErr
+	}
+
+	start := min(params.Offset, len(matched))
+	if params.Cursor != nil {
+		start = len(matched)
+		for i, item := range matched {
+			after, err := isAfter(item, params.Sort, params.Cursor)
+			if err != nil {
+				return page, err
+			}
+			if after {
+				start = i
+				break
+			}
+		}
+	}
+	end := min(start+params.Limit+1, len(matched))
+	page.Items = append(page.Items, matched[start:end]...)
+	return finish(page, params)
+}
+
+func matches(item any, filters []Filter) (bool, error) {
+	for _, f := range filters {
+		value, err := columnValue(item, f.Column)
+		if err != nil {
+			return false, err
+		}
+
+		ok := false
+		switch f.Operator {
+		case Eq, In:
+			for _, v := range f.Values {
+				if cmp, err := compare(value, v); err == nil && cmp == 0 && deref(value) != nil {
+					ok = true
+					break
+				}
+			}
+		case Like:
+			ok = strings.Contains(strings.ToLower(formatValue(value)), strings.ToLower(f.Values[0]))
+		case Gte:
+			cmp, err := compare(value, f.Values[0])
+			ok = err == nil && cmp >= 0 && deref(value) != nil
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func compareItems(a, b any, sort []Sort) (int, error) {
+	for _, s := range sort {
+		va, err := columnValue(a, s.Column)
+		if err != nil {
+			return 0, err
+		}
+		vb, err := columnValue(b, s.Column)
+		if err != nil {
+			return 0, err
+		}
+		cmp, err := compareNullable(va, formatNullable(vb))
+		if err != nil {
+			return 0, err
+		}
+		if s.Desc {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp, nil
+		}
+	}
+	return 0, nil
+}
+
+// isAfter reports whether item sorts after the cursor position.
+func isAfter(item any, sort []Sort, cursor []*string) (bool, error) {
+	for i, s := range sort {
+		value, err := columnValue(item, s.Column)
+		if err != nil {
+			return false, err
+		}
+		cmp, err := compareNullable(value, cursor[i])
+		if err != nil {
+			return false, err
+		}
+		if s.Desc {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp > 0, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,106 @@
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testItems = []testItem{
+	{ID: 1, Name: "Ana", Score: score(10), CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	{ID: 2, Name: "Bruno", Score: nil, CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+	{ID: 3, Name: "Carla", Score: score(5), CreatedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+	{ID: 4, Name: "anabel", Score: nil, CreatedAt: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+	{ID: 5, Name: "Diego", Score: score(10), CreatedAt: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)},
+}
+
+func ids(items []testItem) []uint {
+	out := make([]uint, len(items))
+	for i, item := range items {
+		out[i] = item.ID
+	}
+	return out
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []uint
+		total int64
+	}{
+		{"", []uint{5, 4, 3, 2, 1}, 5},
+		{"sort=id", []uint{1, 2, 3, 4, 5}, 5},
+		{"name[like]=ana&sort=id", []uint{1, 4}, 2},
+		{"id[in]=2,3,9&sort=id", []uint{2, 3}, 2},
+		{"score=10&sort=id", []uint{1, 5}, 2},
+		{"score[gte]=6&sort=id", []uint{1, 5}, 2},
+		{"sort=score", []uint{3, 1, 5, 2, 4}, 5},
+		{"sort=-score", []uint{2, 4, 1, 5, 3}, 5},
+		{"sort=-score,-id", []uint{4, 2, 5, 1, 3}, 5},
+		{"sort=id&offset=3", []uint{4, 5}, 5},
+		{"sort=id&offset=10", []uint{}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			params, err := Parse(values, testSchema)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.query, err)
+			}
+			page, err := Apply(testItems, params)
+			if err != nil {
+				t.Fatalf("Apply error: %v", err)
+			}
+			if got := ids(page.Items); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ids = %v, want %v", got, tt.want)
+			}
+			if page.Total != tt.total {
+				t.Errorf("total = %d, want %d", page.Total, tt.total)
+			}
+		})
+	}
+}
+
+// TestApplyCursor walks every page by following NextCursor, NULLs included.
+func TestApplyCursor(t *testing.T) {
+	tests := []struct {
+		sort string
+		want []uint
+	}{
+		{"id", []uint{1, 2, 3, 4, 5}},
+		{"score", []uint{3, 1, 5, 2, 4}},
+		{"-score", []uint{2, 4, 1, 5, 3}},
+		{"name,-id", []uint{1, 2, 3, 5, 4}},
+		{"-created_at", []uint{5, 4, 3, 2, 1}},
+	}
+	for _, tt := range tests {
+		for _, limit := range []string{"1", "2", "3"} {
+			t.Run(tt.sort+"/"+limit, func(t *testing.T) {
+				values := url.Values{"sort": {tt.sort}, "limit": {limit}}
+				var got []uint
+				for pages := 0; ; pages++ {
+					if pages > len(testItems) {
+						t.Fatalf("cursor did not end after %d pages: %v", pages, got)
+					}
+					params, err := Parse(values, testSchema)
+					if err != nil {
+						t.Fatalf("Parse(%v) error: %v", values, err)
+					}
+					page, err := Apply(testItems, params)
+					if err != nil {
+						t.Fatalf("Apply error: %v", err)
+					}
+					got = append(got, ids(page.Items)...)
+					if page.NextCursor == "" {
+						break
+					}
+					values.Set("cursor", page.NextCursor)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ids = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
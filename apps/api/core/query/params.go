@@ -0,0 +1,243 @@
+/*
+Package query parses the pagination, filtering and sorting parameters shared by
+every list endpoint and applies them to GORM queries or in-memory slices:
+
+	GET /api/users?user_name[like]=ali&id[in]=1,2,3&sort=-created_at,user_name&limit=20
+	GET /api/users?cursor=WyIyMDI2Li4uIl0&limit=20
+	GET /api/users?offset=40&limit=20
+
+Filters are "field=value" (eq) or "field[op]=value" with op one of eq, in
+(comma separated), like (case-insensitive contains) and gte. Only the fields
+and operators a Schema whitelists are accepted, and values, cursors included,
+must parse as the field's Type.
+*/
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Operator string
+
+const (
+	Eq   Operator = "eq"
+	In   Operator = "in"
+	Like Operator = "like"
+	Gte  Operator = "gte"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+// reserved are the parameters that are never read as filters.
+var reserved = []string{"limit", "offset", "cursor", "sort"}
+
+var ErrInvalidQuery = errors.New("invalid query parameter")
+
+// ParamError is a query parameter that could not be applied.
+type ParamError struct {
+	Param  string
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s %q: %s", ErrInvalidQuery, e.Param, e.Reason)
+}
+
+func (e *ParamError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// Field is a resource attribute clients may filter or sort on.
+type Field struct {
+	Column string
+	// Type is TypeString unless set.
+	Type      Type
+	Operators []Operator
+	Sortable  bool
+}
+
+// Schema whitelists the fields of a list endpoint by their public name.
+type Schema struct {
+	Fields map[string]Field
+	// DefaultSort applies when the request has no sort parameter. The id
+	// column is always appended so cursors are unambiguous.
+	DefaultSort []Sort
+}
+
+type Filter struct {
+	Column   string
+	Operator Operator
+	Values   []string
+}
+
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Params is a parsed, validated list request. Columns are already resolved
+// from the schema, so they are safe to put in SQL.
+type Params struct {
+	Limit  int
+	Offset int
+	// Cursor holds the sort column values to start after; nil is NULL.
+	Cursor  []*string
+	Filters []Filter
+	Sort    []Sort
+}
+
+// Parse reads the list parameters of a request. Unknown plain parameters are
+// ignored so endpoints can take their own, but unknown fields in filter
+// syntax and operators outside the schema are errors.
+func Parse(values url.Values, schema Schema) (Params, error) {
+	params := Params{Limit: defaultLimit}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return Params{}, &ParamError{"limit", "must be a positive integer"}
+		}
+		params.Limit = min(limit, maxLimit)
+	}
+
+	if raw := values.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return Params{}, &ParamError{"offset", "must be a non-negative integer"}
+		}
+		params.Offset = offset
+	}
+
+	if raw := values.Get("cursor"); raw != "" {
+		if values.Has("offset") {
+			return Params{}, &ParamError{"cursor", "cannot be combined with offset"}
+		}
+		cursor, err := decodeCursor(raw)
+		if err != nil {
+			return Params{}, &ParamError{"cursor", "is malformed"}
+		}
+		params.Cursor = cursor
+	}
+
+	sort, err := parseSort(values.Get("sort"), schema)
+	if err != nil {
+		return Params{}, err
+	}
+	params.Sort = sort
+
+	filters, err := parseFilters(values, schema)
+	if err != nil {
+		return Params{}, err
+	}
+	params.Filters = filters
+
+	if params.Cursor != nil {
+		if len(params.Cursor) != len(params.Sort) {
+			return Params{}, &ParamError{"cursor", "does not match the sort order"}
+		}
+		for i, s := range params.Sort {
+			if params.Cursor[i] == nil {
+				continue
+			}
+			value, ok := schema.columnType(s.Column).normalize(*params.Cursor[i])
+			if !ok {
+				return Params{}, &ParamError{"cursor", "is malformed"}
+			}
+			params.Cursor[i] = &value
+		}
+	}
+	return params, nil
+}
+
+func parseSort(raw string, schema Schema) ([]Sort, error) {
+	var sort []Sort
+	if raw == "" {
+		sort = append(sort, schema.DefaultSort...)
+	}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := schema.Fields[name]
+		if !ok || !field.Sortable {
+			return nil, &ParamError{"sort", fmt.Sprintf("cannot sort by %q", name)}
+		}
+		sort = append(sort, Sort{Column: field.Column, Desc: desc})
+	}
+
+	if !slices.ContainsFunc(sort, func(s Sort) bool { return s.Column == "id" }) {
+		sort = append(sort, Sort{Column: "id"})
+	}
+	return sort, nil
+}
+
+func parseFilters(values url.Values, schema Schema) ([]Filter, error) {
+	// Sorted so the generated SQL, and its prepared statement, is stable.
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var filters []Filter
+	for _, key := range keys {
+		if slices.Contains(reserved, key) {
+			continue
+		}
+
+		name, op := key, Eq
+		if open := strings.IndexByte(key, '['); open > 0 && strings.HasSuffix(key, "]") {
+			name, op = key[:open], Operator(key[open+1:len(key)-1])
+		}
+		field, ok := schema.Fields[name]
+		if !ok {
+			if name != key {
+				return nil, &ParamError{key, "unknown filter field"}
+			}
+			continue
+		}
+		if !slices.Contains(field.Operators, op) {
+			return nil, &ParamError{key, fmt.Sprintf("operator %q is not allowed", op)}
+		}
+
+		for _, value := range values[key] {
+			filter := Filter{Column: field.Column, Operator: op, Values: []string{value}}
+			if op == In {
+				filter.Values = strings.Split(value, ",")
+			}
+			if op != Like {
+				for i, v := range filter.Values {
+					normalized, ok := field.Type.normalize(v)
+					if !ok {
+						return nil, &ParamError{key, "must be " + field.Type.String()}
+					}
+					filter.Values[i] = normalized
+				}
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return filters, nil
+}
+
+// Page is one page of a list and what the client needs to fetch the next.
+type Page[T any] struct {
+	Items  []T
+	Total  int64
+	Limit  int
+	Offset int
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
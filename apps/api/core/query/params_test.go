@@ -0,0 +1,140 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+var testSchema = Schema{
+	Fields: map[string]Field{
+		"id":         {Column: "id", Type: TypeInt, Operators: []Operator{Eq, In}, Sortable: true},
+		"name":       {Column: "name", Operators: []Operator{Eq, Like}, Sortable: true},
+		"score":      {Column: "score", Type: TypeInt, Operators: []Operator{Eq, Gte}, Sortable: true},
+		"active":     {Column: "active", Type: TypeBool, Operators: []Operator{Eq}},
+		"created_at": {Column: "created_at", Type: TypeTime, Operators: []Operator{Gte}, Sortable: true},
+	},
+	DefaultSort: []Sort{{Column: "created_at", Desc: true}},
+}
+
+func ptr(s string) *string { return &s }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  Params
+	}{
+		{"defaults", "", Params{
+			Limit: defaultLimit,
+			Sort:  []Sort{{Column: "created_at", Desc: true}, {Column: "id"}},
+		}},
+		{"limit and offset", "limit=10&offset=20", Params{
+			Limit:  10,
+			Offset: 20,
+			Sort:   []Sort{{Column: "created_at", Desc: true}, {Column: "id"}},
+		}},
+		{"limit capped", "limit=1000", Params{
+			Limit: maxLimit,
+			Sort:  []Sort{{Column: "created_at", Desc: true}, {Column: "id"}},
+		}},
+		{"sort", "sort=-score,name", Params{
+			Limit: defaultLimit,
+			Sort:  []Sort{{Column: "score", Desc: true}, {Column: "name"}, {Column: "id"}},
+		}},
+		{"sort by id", "sort=-id", Params{
+			Limit: defaultLimit,
+			Sort:  []Sort{{Column: "id", Desc: true}},
+		}},
+		{"filters", "name[like]=Ana%25&id[in]=3,4&score[gte]=007&active=1&other=x", Params{
+			Limit: defaultLimit,
+			Sort:  []Sort{{Column: "created_at", Desc: true}, {Column: "id"}},
+			Filters: []Filter{
+				{Column: "active", Operator: Eq, Values: []string{"true"}},
+				{Column: "id", Operator: In, Values: []string{"3", "4"}},
+				{Column: "name", Operator: Like, Values: []string{"Ana%"}},
+				{Column: "score", Operator: Gte, Values: []string{"7"}},
+			},
+		}},
+		{"time filter in UTC", "created_at[gte]=2026-03-05T10:00:00-05:00", Params{
+			Limit:   defaultLimit,
+			Sort:    []Sort{{Column: "created_at", Desc: true}, {Column: "id"}},
+			Filters: []Filter{{Column: "created_at", Operator: Gte, Values: []string{"2026-03-05T15:00:00Z"}}},
+		}},
+		{"date filter", "created_at[gte]=2026-03-05", Params{
+			Limit:   defaultLimit,
+			Sort:    []Sort{{Column: "created_at", Desc: true}, {Column: "id"}},
+			Filters: []Filter{{Column: "created_at", Operator: Gte, Values: []string{"2026-03-05T00:00:00Z"}}},
+		}},
+		{"cursor", "sort=score&cursor=" + encodeCursor([]*string{ptr("010"), ptr("3")}), Params{
+			Limit:  defaultLimit,
+			Sort:   []Sort{{Column: "score"}, {Column: "id"}},
+			Cursor: []*string{ptr("10"), ptr("3")},
+		}},
+		{"cursor with NULL", "cursor=" + encodeCursor([]*string{nil, ptr("3")}), Params{
+			Limit:  defaultLimit,
+			Sort:   []Sort{{Column: "created_at", Desc: true}, {Column: "id"}},
+			Cursor: []*string{nil, ptr("3")},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Parse(values, testSchema)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		param string
+	}{
+		{"zero limit", "limit=0", "limit"},
+		{"bad limit", "limit=ten", "limit"},
+		{"negative offset", "offset=-1", "offset"},
+		{"cursor and offset", "offset=10&cursor=" + encodeCursor([]*string{ptr("1")}), "cursor"},
+		{"cursor not base64", "cursor=!!", "cursor"},
+		{"cursor not a list", "cursor=" + encodeCursor(nil), "cursor"},
+		{"cursor length", "sort=score&cursor=" + encodeCursor([]*string{ptr("1")}), "cursor"},
+		{"cursor type", "sort=score&cursor=" + encodeCursor([]*string{ptr(""), ptr("1")}), "cursor"},
+		{"cursor id type", "sort=name&cursor=" + encodeCursor([]*string{ptr("Ana"), ptr("x")}), "cursor"},
+		{"unknown sort", "sort=email", "sort"},
+		{"unsortable", "sort=active", "sort"},
+		{"unknown filter field", "email[eq]=x", "email[eq]"},
+		{"operator not allowed", "name[gte]=x", "name[gte]"},
+		{"bad int", "id=abc", "id"},
+		{"bad int in list", "id[in]=1,two", "id[in]"},
+		{"bad time", "created_at[gte]=yesterday", "created_at[gte]"},
+		{"bad bool", "active=maybe", "active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Parse(values, testSchema)
+			var paramErr *ParamError
+			if !errors.As(err, &paramErr) || paramErr.Param != tt.param {
+				t.Fatalf("Parse(%q) error = %v, want a ParamError for %q", tt.query, err, tt.param)
+			}
+			if !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("Parse(%q) error does not wrap ErrInvalidQuery", tt.query)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"strconv"
+	"time"
+)
+
+// Type is the column type of a Field. Filter and cursor values are parsed as
+// it by Parse, so a value Postgres could not cast is a 400, not a 500.
+type Type int
+
+const (
+	TypeString Type = iota
+	TypeInt
+	TypeTime
+	TypeBool
+)
+
+// normalize parses value as t and returns it in the form Find and Apply
+// compare it in: integers in base 10 and times as RFC 3339 in UTC.
+func (t Type) normalize(value string) (string, bool) {
+	switch t {
+	case TypeInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatInt(n, 10), true
+	case TypeTime:
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			parsed, err = time.Parse(time.DateOnly, value)
+		}
+		if err != nil {
+			return "", false
+		}
+		return parsed.UTC().Format(time.RFC3339Nano), true
+	case TypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatBool(b), true
+	}
+	return value, true
+}
+
+func (t Type) String() string {
+	switch t {
+	case TypeInt:
+		return "an integer"
+	case TypeTime:
+		return "a date (2006-01-02) or RFC 3339 time"
+	case TypeBool:
+		return "true or false"
+	}
+	return "a string"
+}
+
+// columnType returns the type of the field mapped to column. The id column,
+// appended to every sort, is an integer unless the schema says otherwise.
+func (s Schema) columnType(column string) Type {
+	for _, field := range s.Fields {
+		if field.Column == column {
+			return field.Type
+		}
+	}
+	if column == "id" {
+		return TypeInt
+	}
+	return TypeString
+}
@@ -0,0 +1,44 @@
+package telemetry
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+/*
+Middleware starts a server span per request, continuing the caller's trace
+from the traceparent header, and records http.server.request.duration by
+route, method and status. Health probes and /metrics are left out so
+scrapes do not drown real traffic.
+*/
+func (t *Telemetry) Middleware() gin.HandlerFunc {
+	serviceName := defaultServiceName
+	if t != nil {
+		serviceName = t.serviceName
+	}
+	return otelgin.Middleware(serviceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		path := c.Request.URL.Path
+		return path != "/metrics" && path != "/health" && !strings.HasPrefix(path, "/health/")
+	}))
+}
+
+// Transport wraps base (http.DefaultTransport when nil) so outbound requests
+// get a client span and propagate the current trace to the callee.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
+
+// NewHTTPClient returns a client for calls to other services, traced
+// through Transport. Requests must carry the request context to join its
+// trace (http.NewRequestWithContext).
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: Transport(nil)}
+}
@@ -0,0 +1,197 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"pengi-med-saas/core/config"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+const (
+	ExporterNone       = "none"
+	ExporterOTLP       = "otlp"
+	ExporterStdout     = "stdout"
+	ExporterPrometheus = "prometheus"
+
+	defaultServiceName = "pengi-med-saas"
+)
+
+// Config selects where traces and metrics go. The OTLP exporters read their
+// endpoint and headers from the standard OTEL_EXPORTER_OTLP_* variables.
+type Config struct {
+	ServiceName string
+	// TracesExporters is empty when tracing is off.
+	TracesExporters []string
+	// MetricsExporters serves /metrics when it includes "prometheus".
+	MetricsExporters []string
+	// Output receives the stdout exporters' output; os.Stdout when nil.
+	Output io.Writer
+}
+
+/*
+LoadConfig reads the telemetry configuration from environment variables:
+
+	OTEL_SERVICE_NAME       service name on every span and metric (default "pengi-med-saas")
+	OTEL_TRACES_EXPORTER    comma separated list of otlp, stdout or none (default "none")
+	OTEL_METRICS_EXPORTER   comma separated list of prometheus, otlp, stdout or none (default "prometheus")
+*/
+func LoadConfig() (Config, error) {
+	var l config.Loader
+	cfg := Config{
+		ServiceName:      l.String("OTEL_SERVICE_NAME", defaultServiceName),
+		TracesExporters:  exporters(&l, "OTEL_TRACES_EXPORTER", ExporterNone, ExporterOTLP, ExporterStdout),
+		MetricsExporters: exporters(&l, "OTEL_METRICS_EXPORTER", ExporterPrometheus, ExporterOTLP, ExporterStdout, ExporterPrometheus),
+	}
+	return cfg, l.Err()
+}
+
+func exporters(l *config.Loader, env, defaultValue string, allowed ...string) []string {
+	var names []string
+	for _, name := range l.List(env, defaultValue) {
+		name = strings.ToLower(name)
+		switch {
+		case name == ExporterNone:
+		case slices.Contains(allowed, name):
+			names = append(names, name)
+		default:
+			l.Check(false, "%s: unknown exporter %q", env, name)
+			return nil
+		}
+	}
+	return names
+}
+
+// Telemetry owns the trace and metric providers installed by Setup.
+type Telemetry struct {
+	serviceName    string
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+	metrics        http.Handler
+}
+
+/*
+Setup installs the global tracer and meter providers described by cfg, so
+instrumentation obtained through otel.Tracer and otel.Meter, even before
+Setup, reports to them. With no exporters it leaves the no-op providers in
+place and instrumentation costs next to nothing.
+
+Call Shutdown before exiting to flush what is still buffered.
+*/
+func Setup(ctx context.Context, cfg Config) (*Telemetry, error) {
+	out := cfg.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Telemetry{serviceName: cfg.ServiceName}
+	if len(cfg.TracesExporters) > 0 {
+		options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+		for _, name := range cfg.TracesExporters {
+			exporter, err := newSpanExporter(ctx, name, out)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, sdktrace.WithBatcher(exporter))
+		}
+		t.tracerProvider = sdktrace.NewTracerProvider(options...)
+		otel.SetTracerProvider(t.tracerProvider)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	}
+
+	if len(cfg.MetricsExporters) > 0 {
+		options := []sdkmetric.Option{sdkmetric.WithResource(res)}
+		for _, name := range cfg.MetricsExporters {
+			reader, err := t.newMetricReader(ctx, name, out)
+			if err != nil {
+				t.Shutdown(ctx)
+				return nil, err
+			}
+			options = append(options, sdkmetric.WithReader(reader))
+		}
+		t.meterProvider = sdkmetric.NewMeterProvider(options...)
+		otel.SetMeterProvider(t.meterProvider)
+	}
+	return t, nil
+}
+
+func newSpanExporter(ctx context.Context, name string, out io.Writer) (sdktrace.SpanExporter, error) {
+	switch name {
+	case ExporterOTLP:
+		return otlptracehttp.New(ctx)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(out))
+	}
+	return nil, fmt.Errorf("unknown trace exporter %q", name)
+}
+
+func (t *Telemetry) newMetricReader(ctx context.Context, name string, out io.Writer) (sdkmetric.Reader, error) {
+	switch name {
+	case ExporterPrometheus:
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		t.metrics = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+		return otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	case ExporterOTLP:
+		exporter, err := otlpmetrichttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return sdkmetric.NewPeriodicReader(exporter), nil
+	case ExporterStdout:
+		exporter, err := stdoutmetric.New(stdoutmetric.WithWriter(out))
+		if err != nil {
+			return nil, err
+		}
+		return sdkmetric.NewPeriodicReader(exporter), nil
+	}
+	return nil, fmt.Errorf("unknown metrics exporter %q", name)
+}
+
+// MetricsHandler serves the metrics in Prometheus text format, or is nil
+// when the prometheus exporter is not enabled.
+func (t *Telemetry) MetricsHandler() http.Handler {
+	if t == nil {
+		return nil
+	}
+	return t.metrics
+}
+
+// Shutdown flushes and stops the providers installed by Setup.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	var errs []error
+	if t.tracerProvider != nil {
+		errs = append(errs, t.tracerProvider.Shutdown(ctx))
+	}
+	if t.meterProvider != nil {
+		errs = append(errs, t.meterProvider.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
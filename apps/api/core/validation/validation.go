@@ -0,0 +1,140 @@
+/*
+Package validation turns request binding failures into field-level
+core_errors.FieldError details that envelope translates, instead of the Go
+validator text:
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return envelope.ErrorResponse(http.StatusBadRequest, "Invalid request",
+			core_errors.ErrAuthInvalidRequest.WithDetails(validation.Details(err)))
+	}
+*/
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	core_errors "pengi-med-saas/core/errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// rules maps validator tags to the message that explains them. Tags without
+// an entry use validation.invalid.
+var rules = map[string]core_errors.FieldError{
+	"required": {MessageKey: "validation.required"},
+	"min":      {MessageKey: "validation.min"},
+	"max":      {MessageKey: "validation.max"},
+	"len":      {MessageKey: "validation.len"},
+	"email":    {MessageKey: "validation.email"},
+	"oneof":    {MessageKey: "validation.oneof"},
+	"gte":      {MessageKey: "validation.gte"},
+	"lte":      {MessageKey: "validation.lte"},
+	"maxbytes": {MessageKey: "validation.maxbytes"},
+}
+
+var (
+	invalidRule = core_errors.FieldError{MessageKey: "validation.invalid"}
+	typeRule    = core_errors.FieldError{MessageKey: "validation.type"}
+	syntaxRule  = core_errors.FieldError{MessageKey: "validation.json"}
+	requestRule = core_errors.FieldError{MessageKey: "validation.request"}
+)
+
+func init() {
+	// Report fields by the JSON names clients send, not the Go names.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonName)
+		v.RegisterValidation("maxbytes", maxBytes)
+	}
+}
+
+// maxBytes is max for byte limits, such as bcrypt's 72: max counts characters,
+// and a character may take up to 4 bytes in UTF-8.
+func maxBytes(fl validator.FieldLevel) bool {
+	limit, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		panic("validation: maxbytes needs an integer parameter, got " + fl.Param())
+	}
+	return fl.Field().Kind() == reflect.String && len(fl.Field().String()) <= limit
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// Details describes why binding a request failed, one entry per field.
+func Details(err error) []core_errors.FieldError {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		syntaxErr      *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		details := make([]core_errors.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			details = append(details, fieldError(fe))
+		}
+		return details
+	case errors.As(err, &typeErr):
+		detail := typeRule
+		detail.Field, detail.Rule = typeErr.Field, "type"
+		detail.Params = map[string]any{"type": typeErr.Type.String()}
+		return []core_errors.FieldError{withMessage(detail)}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		detail := syntaxRule
+		detail.Rule = "json"
+		return []core_errors.FieldError{withMessage(detail)}
+	}
+
+	detail := requestRule
+	detail.Rule = "request"
+	return []core_errors.FieldError{withMessage(detail)}
+}
+
+func fieldError(fe validator.FieldError) core_errors.FieldError {
+	detail, ok := rules[fe.Tag()]
+	if !ok {
+		detail = invalidRule
+	}
+	detail.Rule = fe.Tag()
+
+	// Namespace is "loginRequest.user_name"; nested fields keep their path.
+	_, detail.Field, _ = strings.Cut(fe.Namespace(), ".")
+
+	if param := fe.Param(); param != "" {
+		// kind lets messages tell "at least 8 characters" from "at least 8",
+		// and numbers stay numbers so messages can pluralise on them.
+		detail.Params = map[string]any{"param": param, "kind": kind(fe.Kind())}
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			detail.Params["param"] = n
+		}
+	}
+	return withMessage(detail)
+}
+
+func kind(k reflect.Kind) string {
+	switch k {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "list"
+	}
+	return "number"
+}
+
+func withMessage(detail core_errors.FieldError) core_errors.FieldError {
+	detail.Message = detail.MessageKey
+	return detail
+}
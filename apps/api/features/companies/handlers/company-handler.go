@@ -1,35 +1,35 @@
 package company_handlers
 
 import (
-	"net/http"
 	"pengi-med-saas/core/envelope"
-	core_errors "pengi-med-saas/core/errors"
-	company_models "pengi-med-saas/features/companies/models"
+	"pengi-med-saas/core/logger"
+	"pengi-med-saas/core/query"
+	company_repositories "pengi-med-saas/features/companies/repositories"
+	company_services "pengi-med-saas/features/companies/services"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 )
 
 type CompanyHandler struct {
-	db     *gorm.DB
-	logger *zap.Logger
+	service *company_services.CompanyService
 }
 
-func NewCompanyHandler(db *gorm.DB, logger *zap.Logger) *CompanyHandler {
-	return &CompanyHandler{
-		db:     db,
-		logger: logger,
-	}
+func NewCompanyHandler(service *company_services.CompanyService) *CompanyHandler {
+	return &CompanyHandler{service: service}
 }
 
 func (h *CompanyHandler) GetCompanies(c *gin.Context) envelope.Response {
-	companies := []company_models.Company{}
-	if err := h.db.Find(&companies).Error; err != nil {
-		h.logger.Error("Failed to fetch companies", zap.Error(err))
-		return envelope.ErrorResponse(http.StatusInternalServerError, "Error obtaining companies", core_errors.ErrCompanyNotFound)
+	params, err := query.Parse(c.Request.URL.Query(), company_repositories.CompanyQuery)
+	if err != nil {
+		return envelope.InvalidQuery(err)
+	}
+
+	companies, err := h.service.List(c.Request.Context(), params)
+	if err != nil {
+		return envelope.FromError(err)
 	}
 
-	h.logger.Info("Companies fetched successfully", zap.Int("count", len(companies)))
-	return envelope.SuccessResponse(companies, "Companies obtained successfully")
+	logger.FromContext(c.Request.Context()).Info("Companies fetched successfully", zap.Int("count", len(companies.Items)))
+	return envelope.Paginated(companies, "Companies obtained successfully")
 }
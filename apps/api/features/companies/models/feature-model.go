@@ -12,7 +12,3 @@ type Feature struct {
 	Name        string                         `gorm:"not null" json:"name"`
 	Permissions []permission_models.Permission `gorm:"many2many:feature_permissions;" json:"permissions"`
 }
-
-func (f *Feature) Save(db *gorm.DB) error {
-	return db.Save(f).Error
-}
@@ -13,7 +13,3 @@ type Plan struct {
 	Price      float64           `gorm:"not null" json:"price"`
 	Properties datatypes.JSONMap `gorm:"type:jsonb;default:'{}'::jsonb"`
 }
-
-func (p *Plan) Save(db *gorm.DB) error {
-	return db.Save(p).Error
-}
@@ -14,7 +14,3 @@ type Subscription struct {
 	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
 	CompanyID uint
 }
-
-func (s *Subscription) Save(db *gorm.DB) error {
-	return db.Save(s).Error
-}
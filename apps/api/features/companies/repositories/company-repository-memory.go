@@ -0,0 +1,90 @@
+package company_repositories
+
+import (
+	"context"
+	"fmt"
+	"pengi-med-saas/core/query"
+	company_models "pengi-med-saas/features/companies/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryCompanyRepository is an in-memory CompanyRepository for tests. Like
+// Postgres it assigns IDs and rejects duplicated ones.
+type MemoryCompanyRepository struct {
+	mu                 sync.RWMutex
+	companies          []company_models.Company
+	subscriptions      []company_models.Subscription
+	nextID             uint
+	nextSubscriptionID uint
+}
+
+func NewMemoryCompanyRepository(companies ...company_models.Company) *MemoryCompanyRepository {
+	r := &MemoryCompanyRepository{}
+	for _, company := range companies {
+		r.Create(context.Background(), &company)
+	}
+	return r
+}
+
+func (r *MemoryCompanyRepository) List(ctx context.Context, params query.Params) (query.Page[company_models.Company], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return query.Apply(r.companies, params)
+}
+
+func (r *MemoryCompanyRepository) FindByIDs(ctx context.Context, ids []uint) ([]company_models.Company, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	companies := []company_models.Company{}
+	for _, company := range r.companies {
+		if slices.Contains(ids, company.ID) {
+			companies = append(companies, company)
+		}
+	}
+	return companies, nil
+}
+
+func (r *MemoryCompanyRepository) Create(ctx context.Context, company *company_models.Company) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if company.ID == 0 {
+		r.nextID++
+		company.ID = r.nextID
+	} else {
+		for _, existing := range r.companies {
+			if existing.ID == company.ID {
+				return fmt.Errorf("company %d already exists", company.ID)
+			}
+		}
+		r.nextID = max(r.nextID, company.ID)
+	}
+	company.CreatedAt, company.UpdatedAt = time.Now(), time.Now()
+	r.companies = append(r.companies, *company)
+	return nil
+}
+
+func (r *MemoryCompanyRepository) SaveSubscription(ctx context.Context, subscription *company_models.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription.UpdatedAt = time.Now()
+	if subscription.ID != 0 {
+		for i := range r.subscriptions {
+			if r.subscriptions[i].ID == subscription.ID {
+				r.subscriptions[i] = *subscription
+				return nil
+			}
+		}
+	} else {
+		r.nextSubscriptionID++
+		subscription.ID = r.nextSubscriptionID
+		subscription.CreatedAt = subscription.UpdatedAt
+	}
+	r.subscriptions = append(r.subscriptions, *subscription)
+	return nil
+}
@@ -0,0 +1,59 @@
+package company_repositories
+
+import (
+	"context"
+	"pengi-med-saas/core/database"
+	"pengi-med-saas/core/query"
+	company_models "pengi-med-saas/features/companies/models"
+
+	"gorm.io/gorm"
+)
+
+// CompanyQuery is what GET /companies lets clients filter and sort on.
+var CompanyQuery = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.TypeInt, Operators: []query.Operator{query.Eq, query.In}, Sortable: true},
+		"legal_name": {Column: "legal_name", Operators: []query.Operator{query.Eq, query.Like}, Sortable: true},
+		"trade_name": {Column: "trade_name", Operators: []query.Operator{query.Eq, query.Like}, Sortable: true},
+		"plan_code":  {Column: "plan_code", Operators: []query.Operator{query.Eq, query.In}, Sortable: true},
+		"tenant_id":  {Column: "tenant_id", Type: query.TypeInt, Operators: []query.Operator{query.Eq, query.In}},
+		"created_at": {Column: "created_at", Type: query.TypeTime, Operators: []query.Operator{query.Gte}, Sortable: true},
+	},
+	DefaultSort: []query.Sort{{Column: "id"}},
+}
+
+type CompanyRepository interface {
+	List(ctx context.Context, params query.Params) (query.Page[company_models.Company], error)
+	FindByIDs(ctx context.Context, ids []uint) ([]company_models.Company, error)
+	Create(ctx context.Context, company *company_models.Company) error
+	SaveSubscription(ctx context.Context, subscription *company_models.Subscription) error
+}
+
+type GormCompanyRepository struct {
+	db *gorm.DB
+}
+
+func NewGormCompanyRepository(db *gorm.DB) *GormCompanyRepository {
+	return &GormCompanyRepository{db: db}
+}
+
+func (r *GormCompanyRepository) List(ctx context.Context, params query.Params) (query.Page[company_models.Company], error) {
+	return query.Find[company_models.Company](database.FromContext(ctx, r.db), params)
+}
+
+func (r *GormCompanyRepository) FindByIDs(ctx context.Context, ids []uint) ([]company_models.Company, error) {
+	companies := []company_models.Company{}
+	if len(ids) == 0 {
+		return companies, nil
+	}
+	err := database.FromContext(ctx, r.db).Where("id IN ?", ids).Order("id").Find(&companies).Error
+	return companies, err
+}
+
+func (r *GormCompanyRepository) Create(ctx context.Context, company *company_models.Company) error {
+	return database.FromContext(ctx, r.db).Create(company).Error
+}
+
+func (r *GormCompanyRepository) SaveSubscription(ctx context.Context, subscription *company_models.Subscription) error {
+	return database.FromContext(ctx, r.db).Save(subscription).Error
+}
@@ -0,0 +1,32 @@
+package company_services
+
+import (
+	"context"
+	"pengi-med-saas/core/query"
+	company_models "pengi-med-saas/features/companies/models"
+	company_repositories "pengi-med-saas/features/companies/repositories"
+)
+
+type CompanyService struct {
+	companies company_repositories.CompanyRepository
+}
+
+func NewCompanyService(companies company_repositories.CompanyRepository) *CompanyService {
+	return &CompanyService{companies: companies}
+}
+
+func (s *CompanyService) List(ctx context.Context, params query.Params) (query.Page[company_models.Company], error) {
+	return s.companies.List(ctx, params)
+}
+
+func (s *CompanyService) FindByIDs(ctx context.Context, ids []uint) ([]company_models.Company, error) {
+	return s.companies.FindByIDs(ctx, ids)
+}
+
+func (s *CompanyService) Create(ctx context.Context, company *company_models.Company) error {
+	return s.companies.Create(ctx, company)
+}
+
+func (s *CompanyService) SaveSubscription(ctx context.Context, subscription *company_models.Subscription) error {
+	return s.companies.SaveSubscription(ctx, subscription)
+}
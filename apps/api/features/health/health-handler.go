@@ -1,12 +1,41 @@
 package health
 
 import (
+	"net/http"
 	"pengi-med-saas/core/envelope"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Health is kept for existing probes; it is the same as Live.
 func Health(c *gin.Context) {
+	Live(c)
+}
+
+// Live answers as long as the process can serve requests. It checks nothing
+// else, so a database outage does not get every replica restarted.
+func Live(c *gin.Context) {
 	response := envelope.SuccessResponse(nil, "ok")
 	c.JSON(response.Code, response)
 }
+
+/*
+Ready runs the checker and answers 200 when every check is up, or 503 with the
+same per-check report so the orchestrator stops routing to this instance. A
+nil checker has nothing to check and is always ready.
+*/
+func Ready(checker *Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := Report{Status: StatusUp, Checks: map[string]CheckResult{}}
+		if checker != nil {
+			report = checker.Run(c.Request.Context())
+		}
+
+		if report.Status != StatusUp {
+			c.JSON(http.StatusServiceUnavailable, envelope.New(http.StatusServiceUnavailable, "not ready", report))
+			return
+		}
+		response := envelope.SuccessResponse(report, "ok")
+		c.JSON(response.Code, response)
+	}
+}
@@ -2,8 +2,6 @@ package permission_models
 
 import (
 	"pengi-med-saas/core/database"
-
-	"gorm.io/gorm"
 )
 
 type Permission struct {
@@ -11,7 +9,3 @@ type Permission struct {
 	Name     string `json:"name"`
 	Category string `json:"category"`
 }
-
-func (p *Permission) Save(db *gorm.DB) error {
-	return db.Create(&p).Error
-}
@@ -0,0 +1,62 @@
+package permission_repositories
+
+import (
+	"context"
+	"fmt"
+	permission_models "pengi-med-saas/features/permissions/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryPermissionRepository is an in-memory PermissionRepository for tests.
+type MemoryPermissionRepository struct {
+	mu          sync.RWMutex
+	permissions map[string]permission_models.Permission
+}
+
+func NewMemoryPermissionRepository(permissions ...permission_models.Permission) *MemoryPermissionRepository {
+	r := &MemoryPermissionRepository{permissions: make(map[string]permission_models.Permission)}
+	for _, permission := range permissions {
+		r.Create(context.Background(), &permission)
+	}
+	return r
+}
+
+func (r *MemoryPermissionRepository) List(ctx context.Context) ([]permission_models.Permission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	permissions := make([]permission_models.Permission, 0, len(r.permissions))
+	for _, permission := range r.permissions {
+		permissions = append(permissions, permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].ID < permissions[j].ID })
+	return permissions, nil
+}
+
+func (r *MemoryPermissionRepository) FindByIDs(ctx context.Context, ids []string) ([]permission_models.Permission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	permissions := []permission_models.Permission{}
+	for _, id := range ids {
+		if permission, ok := r.permissions[id]; ok {
+			permissions = append(permissions, permission)
+		}
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].ID < permissions[j].ID })
+	return permissions, nil
+}
+
+func (r *MemoryPermissionRepository) Create(ctx context.Context, permission *permission_models.Permission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.permissions[permission.ID]; exists {
+		return fmt.Errorf("permission %q already exists", permission.ID)
+	}
+	permission.CreatedAt, permission.UpdatedAt = time.Now(), time.Now()
+	r.permissions[permission.ID] = *permission
+	return nil
+}
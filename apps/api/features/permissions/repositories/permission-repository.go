@@ -0,0 +1,39 @@
+package permission_repositories
+
+import (
+	"context"
+	"pengi-med-saas/core/database"
+	permission_models "pengi-med-saas/features/permissions/models"
+
+	"gorm.io/gorm"
+)
+
+type PermissionRepository interface {
+	List(ctx context.Context) ([]permission_models.Permission, error)
+	FindByIDs(ctx context.Context, ids []string) ([]permission_models.Permission, error)
+	Create(ctx context.Context, permission *permission_models.Permission) error
+}
+
+type GormPermissionRepository struct {
+	db *gorm.DB
+}
+
+func NewGormPermissionRepository(db *gorm.DB) *GormPermissionRepository {
+	return &GormPermissionRepository{db: db}
+}
+
+func (r *GormPermissionRepository) List(ctx context.Context) ([]permission_models.Permission, error) {
+	permissions := []permission_models.Permission{}
+	err := database.FromContext(ctx, r.db).Order("id").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *GormPermissionRepository) FindByIDs(ctx context.Context, ids []string) ([]permission_models.Permission, error) {
+	permissions := []permission_models.Permission{}
+	err := database.FromContext(ctx, r.db).Where("id IN ?", ids).Order("id").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *GormPermissionRepository) Create(ctx context.Context, permission *permission_models.Permission) error {
+	return database.FromContext(ctx, r.db).Create(permission).Error
+}
@@ -4,28 +4,37 @@ import (
 	"net/http"
 	"pengi-med-saas/core/envelope"
 	core_errors "pengi-med-saas/core/errors"
-	tenant_models "pengi-med-saas/features/tenants/models"
+	"pengi-med-saas/core/logger"
+	tenant_services "pengi-med-saas/features/tenants/services"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
-func TenantMiddleware(db *gorm.DB) gin.HandlerFunc {
+func TenantMiddleware(service *tenant_services.TenantService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Already resolved by I18nMiddleware.
+		if _, resolved := c.Get("tenant_id"); resolved {
+			c.Next()
+			return
+		}
+
 		slug := c.GetHeader("X-Tenant-Slug")
 
 		if slug == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, envelope.ErrorResponse(http.StatusBadRequest, "X-Tenant-Slug header is missing", core_errors.ErrTenantNotFound))
+			envelope.Abort(c, envelope.ErrorResponse(http.StatusBadRequest, "X-Tenant-Slug header is missing", core_errors.ErrTenantNotFound))
 			return
 		}
 
-		var tenant tenant_models.Tenant
-		if err := db.Where("slug = ?", slug).First(&tenant).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, envelope.ErrorResponse(http.StatusNotFound, "Tenant not found", core_errors.ErrTenantNotFound))
+		tenant, err := service.FindBySlug(c.Request.Context(), slug)
+		if err != nil {
+			envelope.Abort(c, envelope.ErrorResponse(http.StatusNotFound, "Tenant not found", core_errors.ErrTenantNotFound))
 			return
 		}
 
 		c.Set("tenant_id", tenant.ID)
+		c.Request = c.Request.WithContext(logger.With(c.Request.Context(), zap.Uint("tenant_id", tenant.ID)))
 		c.Next()
 	}
 }
@@ -6,6 +6,9 @@ type Tenant struct {
 	gorm.Model
 	Name string `gorm:"not null"`
 	Slug string `gorm:"not null;unique"`
+	// DefaultLang is used when the client's Accept-Language matches none of
+	// the available languages. Empty means the global default.
+	DefaultLang string `gorm:"not null;default:''"`
 }
 
 func NewTenant(name string) *Tenant {
@@ -13,7 +16,3 @@ func NewTenant(name string) *Tenant {
 		Name: name,
 	}
 }
-
-func (t *Tenant) Save(db *gorm.DB) error {
-	return db.Save(t).Error
-}
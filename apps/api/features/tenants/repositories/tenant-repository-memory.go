@@ -0,0 +1,59 @@
+package tenant_repositories
+
+import (
+	"context"
+	"fmt"
+	tenant_models "pengi-med-saas/features/tenants/models"
+	"sync"
+	"time"
+)
+
+// MemoryTenantRepository is an in-memory TenantRepository for tests. Like
+// Postgres it assigns IDs and rejects duplicated IDs and slugs.
+type MemoryTenantRepository struct {
+	mu      sync.RWMutex
+	tenants map[string]tenant_models.Tenant // slug -> tenant
+	nextID  uint
+}
+
+func NewMemoryTenantRepository(tenants ...tenant_models.Tenant) *MemoryTenantRepository {
+	r := &MemoryTenantRepository{tenants: make(map[string]tenant_models.Tenant)}
+	for _, tenant := range tenants {
+		r.Create(context.Background(), &tenant)
+	}
+	return r
+}
+
+func (r *MemoryTenantRepository) FindBySlug(ctx context.Context, slug string) (*tenant_models.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenant, ok := r.tenants[slug]
+	if !ok {
+		return nil, ErrTenantNotFound
+	}
+	return &tenant, nil
+}
+
+func (r *MemoryTenantRepository) Create(ctx context.Context, tenant *tenant_models.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tenants[tenant.Slug]; exists {
+		return ErrTenantExists
+	}
+	if tenant.ID == 0 {
+		r.nextID++
+		tenant.ID = r.nextID
+	} else {
+		for _, existing := range r.tenants {
+			if existing.ID == tenant.ID {
+				return fmt.Errorf("tenant %d already exists", tenant.ID)
+			}
+		}
+		r.nextID = max(r.nextID, tenant.ID)
+	}
+	tenant.CreatedAt, tenant.UpdatedAt = time.Now(), time.Now()
+	r.tenants[tenant.Slug] = *tenant
+	return nil
+}
@@ -0,0 +1,49 @@
+package tenant_repositories
+
+import (
+	"context"
+	"errors"
+	"pengi-med-saas/core/database"
+	tenant_models "pengi-med-saas/features/tenants/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrTenantExists   = errors.New("a tenant with this slug already exists")
+)
+
+type TenantRepository interface {
+	FindBySlug(ctx context.Context, slug string) (*tenant_models.Tenant, error)
+	Create(ctx context.Context, tenant *tenant_models.Tenant) error
+}
+
+type GormTenantRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTenantRepository(db *gorm.DB) *GormTenantRepository {
+	return &GormTenantRepository{db: db}
+}
+
+func (r *GormTenantRepository) FindBySlug(ctx context.Context, slug string) (*tenant_models.Tenant, error) {
+	var tenant tenant_models.Tenant
+	if err := database.FromContext(ctx, r.db).Where("slug = ?", slug).First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *GormTenantRepository) Create(ctx context.Context, tenant *tenant_models.Tenant) error {
+	err := database.FromContext(ctx, r.db).Create(tenant).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uni_tenants_slug" {
+		return ErrTenantExists
+	}
+	return err
+}
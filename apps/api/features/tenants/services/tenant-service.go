@@ -0,0 +1,23 @@
+package tenant_services
+
+import (
+	"context"
+	tenant_models "pengi-med-saas/features/tenants/models"
+	tenant_repositories "pengi-med-saas/features/tenants/repositories"
+)
+
+type TenantService struct {
+	tenants tenant_repositories.TenantRepository
+}
+
+func NewTenantService(tenants tenant_repositories.TenantRepository) *TenantService {
+	return &TenantService{tenants: tenants}
+}
+
+func (s *TenantService) FindBySlug(ctx context.Context, slug string) (*tenant_models.Tenant, error) {
+	return s.tenants.FindBySlug(ctx, slug)
+}
+
+func (s *TenantService) Create(ctx context.Context, tenant *tenant_models.Tenant) error {
+	return s.tenants.Create(ctx, tenant)
+}
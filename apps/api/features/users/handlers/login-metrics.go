@@ -0,0 +1,18 @@
+package user_handlers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// loginFailures counts rejected logins; a spike of invalid_credentials is
+// what credential stuffing looks like.
+var loginFailures, _ = otel.Meter("pengi-med-saas/features/users").Int64Counter("auth.login.failures",
+	metric.WithDescription("Failed login attempts by reason."))
+
+func recordLoginFailure(ctx context.Context, reason string) {
+	loginFailures.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+}
@@ -4,133 +4,160 @@ import (
 	"errors"
 	"net/http"
 	"pengi-med-saas/core/auth"
+	"pengi-med-saas/core/database"
 	"pengi-med-saas/core/envelope"
 	core_errors "pengi-med-saas/core/errors"
+	"pengi-med-saas/core/logger"
+	"pengi-med-saas/core/query"
+	"pengi-med-saas/core/validation"
 	user_models "pengi-med-saas/features/users/models"
+	user_repositories "pengi-med-saas/features/users/repositories"
+	user_services "pengi-med-saas/features/users/services"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 )
 
+type signUpRequest struct {
+	UserName string `json:"user_name" binding:"required,min=3,max=50"`
+	// bcrypt rejects passwords longer than 72 bytes.
+	Password string `json:"password" binding:"required,min=8,maxbytes=72"`
+	Email    string `json:"email" binding:"required,email"`
+}
+
+type loginRequest struct {
+	UserName string `json:"user_name" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
 type UserHandler struct {
-	db     *gorm.DB
-	logger *zap.Logger
+	service *user_services.UserService
+	tokens  *auth.Tokens
 }
 
-func NewUserHandler(db *gorm.DB, logger *zap.Logger) *UserHandler {
-	return &UserHandler{
-		db:     db,
-		logger: logger,
-	}
+func NewUserHandler(service *user_services.UserService, tokens *auth.Tokens) *UserHandler {
+	return &UserHandler{service: service, tokens: tokens}
 }
 
 func (h *UserHandler) GetUsers(c *gin.Context) envelope.Response {
-	users := []user_models.User{}
-	if err := h.db.Find(&users).Error; err != nil {
-		h.logger.Error("Failed to fetch users", zap.Error(err))
-		return envelope.ErrorResponse(http.StatusInternalServerError, "Error obtaining users", core_errors.ErrUserNotFound)
+	params, err := query.Parse(c.Request.URL.Query(), user_repositories.UserQuery)
+	if err != nil {
+		return envelope.InvalidQuery(err)
 	}
 
-	h.logger.Info("Users fetched successfully", zap.Int("count", len(users)))
-	return envelope.SuccessResponse(users, "Users obtained successfully")
+	users, err := h.service.List(c.Request.Context(), params)
+	if err != nil {
+		return envelope.FromError(err)
+	}
+
+	logger.FromContext(c.Request.Context()).Info("Users fetched successfully", zap.Int("count", len(users.Items)))
+	return envelope.Paginated(users, "Users obtained successfully")
 }
 
 func (h *UserHandler) SignUp(c *gin.Context) envelope.Response {
-	var user user_models.User
-	if err := c.ShouldBind(&user); err != nil {
-		h.logger.Error("Invalid signup request", zap.Error(err))
-		return envelope.ErrorResponse(http.StatusBadRequest, err.Error(), core_errors.ErrAuthInvalidRequest)
+	var req signUpRequest
+	if err := c.ShouldBind(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Info("Invalid signup request", zap.Error(err))
+		return envelope.ErrorResponse(http.StatusBadRequest, "Invalid signup request",
+			core_errors.ErrAuthInvalidRequest.WithDetails(validation.Details(err)))
 	}
-	if err := user.Save(h.db); err != nil {
-		h.logger.Error("Failed to create user", zap.Error(err))
-		return envelope.ErrorResponse(http.StatusInternalServerError, err.Error(), core_errors.ErrAuthUserCreateError)
+
+	user := user_models.User{UserName: req.UserName, Password: req.Password, Email: req.Email}
+	if err := h.service.SignUp(c.Request.Context(), &user); err != nil {
+		return envelope.FromError(core_errors.ErrAuthUserCreateError.Wrap(err))
 	}
 	return envelope.SuccessResponse(user, "User created successfully")
 }
 
 func (h *UserHandler) Login(c *gin.Context) envelope.Response {
 	// 1) Bind
-	var user user_models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		h.logger.Error("Invalid login request", zap.Error(err))
-		return envelope.ErrorResponse(http.StatusBadRequest, err.Error(), core_errors.ErrAuthInvalidRequest)
+	var credentials loginRequest
+	if err := c.ShouldBindJSON(&credentials); err != nil {
+		logger.FromContext(c.Request.Context()).Info("Invalid login request", zap.Error(err))
+		recordLoginFailure(c.Request.Context(), "invalid_request")
+		return envelope.ErrorResponse(http.StatusBadRequest, "Invalid login request",
+			core_errors.ErrAuthInvalidRequest.WithDetails(validation.Details(err)))
+	}
+
+	// 2) Validar credenciales (siempre contra el primario: el usuario puede
+	// haberse registrado hace instantes y no estar replicado aún)
+	database.ForcePrimary(c.Request.Context())
+	user, err := h.service.Authenticate(c.Request.Context(), credentials.UserName, credentials.Password)
+	if errors.Is(err, user_services.ErrInvalidCredentials) {
+		logger.FromContext(c.Request.Context()).Warn("Failed login attempt", zap.String("username", credentials.UserName))
+		recordLoginFailure(c.Request.Context(), "invalid_credentials")
+		return envelope.FromError(core_errors.ErrAuthInvalidCredentials.Wrap(err))
 	}
-
-	// 2) Validar credenciales
-	if err := user.ValidateCredentials(h.db); err != nil {
-		h.logger.Warn("Failed login attempt", zap.String("username", user.UserName), zap.Error(err))
-		return envelope.ErrorResponse(http.StatusUnauthorized, err.Error(), core_errors.ErrAuthInvalidCredentials)
+	if err != nil {
+		return envelope.FromError(err)
 	}
 
 	// 3) Generar tokens
-	token, err := auth.GenerateToken(user.UserName, int64(user.ID))
+	token, err := h.tokens.GenerateToken(user.UserName, int64(user.ID))
 	if err != nil {
-		return envelope.ErrorResponse(http.StatusInternalServerError, err.Error(), core_errors.ErrAuthTokenGenerateError)
+		return envelope.FromError(core_errors.ErrAuthTokenGenerateError.Wrap(err))
 	}
 
-	refreshToken, err := auth.GenerateRefreshToken(user.UserName, int64(user.ID))
+	refreshToken, err := h.tokens.GenerateRefreshToken(user.UserName, int64(user.ID))
 	if err != nil {
-		h.logger.Error("Failed to generate refresh token", zap.Error(err))
-		return envelope.ErrorResponse(http.StatusInternalServerError, err.Error(), core_errors.ErrAuthTokenGenerateError)
+		return envelope.FromError(core_errors.ErrAuthTokenGenerateError.Wrap(err))
 	}
 
 	// 4) Guardar refresh token (chequear error)
-	if err := user.UpdateRefreshToken(h.db, refreshToken); err != nil {
-		return envelope.ErrorResponse(http.StatusInternalServerError, err.Error(), core_errors.ErrAuthTokenGenerateError)
+	if err := h.service.UpdateRefreshToken(c.Request.Context(), user, refreshToken); err != nil {
+		return envelope.FromError(core_errors.ErrAuthTokenGenerateError.Wrap(err))
 	}
 
 	// 5) Setear cookie y responder 200 una sola vez
-	auth.SetRefreshTokenCookie(refreshToken, c)
+	h.tokens.SetRefreshTokenCookie(refreshToken, c)
 
-	h.logger.Info("User logged in successfully", zap.String("username", user.UserName))
+	logger.FromContext(c.Request.Context()).Info("User logged in successfully", zap.String("username", user.UserName))
 	return envelope.SuccessResponse(gin.H{"token": token, "user_id": user.ID}, "Login successful")
 }
 
 func (h *UserHandler) RefreshAuthToken(c *gin.Context) envelope.Response {
 	refreshToken, err := c.Cookie("refresh_token")
 	if err != nil {
-		return envelope.ErrorResponse(http.StatusBadRequest, err.Error(), core_errors.ErrAuthInvalidRefreshToken)
+		return envelope.FromError(core_errors.ErrAuthInvalidRefreshToken.Wrap(err))
 	}
-	userID, username, err := auth.ValidateRefreshToken(refreshToken)
+	userID, username, err := h.tokens.ValidateRefreshToken(refreshToken)
 	if err != nil {
-		return envelope.ErrorResponse(http.StatusBadRequest, err.Error(), core_errors.ErrAuthInvalidRefreshToken)
+		return envelope.FromError(core_errors.ErrAuthInvalidRefreshToken.Wrap(err))
 	}
-	token, err := auth.GenerateToken(username, int64(userID))
+	token, err := h.tokens.GenerateToken(username, int64(userID))
 	if err != nil {
-		h.logger.Error("Failed to generate token during refresh", zap.Error(err))
-		return envelope.ErrorResponse(http.StatusInternalServerError, err.Error(), core_errors.ErrAuthTokenGenerateError)
+		return envelope.FromError(core_errors.ErrAuthTokenGenerateError.Wrap(err))
 	}
-	h.logger.Info("Token refreshed successfully", zap.String("username", username))
+	logger.FromContext(c.Request.Context()).Info("Token refreshed successfully", zap.String("username", username))
 	return envelope.SuccessResponse(gin.H{"token": token, "user_id": userID}, "Token refreshed successfully")
 }
 
 func (h *UserHandler) ExtendSession(c *gin.Context) envelope.Response {
-	userId := c.GetInt64("userId")
-	var user user_models.User
-	// Assuming logic matches user snippet: finding user by ID
-	if err := h.db.Model(&user_models.User{}).First(&user, userId).Error; err != nil {
-		h.logger.Error("Failed to find user for session extension", zap.Int64("userId", userId), zap.Error(err))
-		return envelope.ErrorResponse(http.StatusBadRequest, err.Error(), core_errors.ErrAuthUserInvalidID)
-	}
-	token, err := auth.GenerateToken(user.UserName, int64(user.ID))
+	userId := c.GetInt64("user_id")
+	user, err := h.service.FindByID(c.Request.Context(), uint(userId))
+	if errors.Is(err, user_repositories.ErrUserNotFound) {
+		return envelope.FromError(core_errors.ErrAuthUserInvalidID.Wrap(err))
+	}
+	if err != nil {
+		return envelope.FromError(err)
+	}
+	token, err := h.tokens.GenerateToken(user.UserName, int64(user.ID))
 	if err != nil {
-		h.logger.Error("Failed to generate token for session extension", zap.Error(err))
-		return envelope.ErrorResponse(http.StatusInternalServerError, err.Error(), core_errors.ErrAuthTokenGenerateError)
+		return envelope.FromError(core_errors.ErrAuthTokenGenerateError.Wrap(err))
 	}
 
-	h.logger.Info("Session extended successfully", zap.String("username", user.UserName))
+	logger.FromContext(c.Request.Context()).Info("Session extended successfully", zap.String("username", user.UserName))
 	return envelope.SuccessResponse(gin.H{"token": token, "user_id": user.ID}, "Session extended successfully")
 }
 
 func (h *UserHandler) ValidateBearerToken(c *gin.Context) envelope.Response {
 	// Usar helper para extraer y validar el token
-	claims, token, err := ExtractAndValidateBearerToken(c)
+	claims, token, err := ExtractAndValidateBearerToken(c, h.tokens)
 	if err != nil {
 		// ExtractAndValidateBearerToken returns error which we map
-		h.logger.Warn("Bearer token validation failed", zap.Error(err))
-		return envelope.ErrorResponse(http.StatusUnauthorized, err.Error(), core_errors.ErrAuthInvalidRequest)
+		logger.FromContext(c.Request.Context()).Warn("Bearer token validation failed", zap.Error(err))
+		return envelope.ErrorResponse(http.StatusUnauthorized, "Invalid or expired token", core_errors.ErrAuthInvalidRequest)
 	}
 
 	// Extraer información del token
@@ -156,7 +183,7 @@ func (h *UserHandler) ValidateBearerToken(c *gin.Context) envelope.Response {
 
 // ExtractAndValidateBearerToken es una función helper que extrae y valida un Bearer token
 // Retorna (claims, token, error)
-func ExtractAndValidateBearerToken(c *gin.Context) (map[string]interface{}, string, error) {
+func ExtractAndValidateBearerToken(c *gin.Context, tokens *auth.Tokens) (map[string]interface{}, string, error) {
 	// 1) Extraer el token del header Authorization
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
@@ -175,7 +202,7 @@ func ExtractAndValidateBearerToken(c *gin.Context) (map[string]interface{}, stri
 	}
 
 	// 4) Validar el token usando ParseToken
-	claims, err := auth.ParseToken(token)
+	claims, err := tokens.ParseToken(token)
 	if err != nil {
 		return nil, "", err
 	}
@@ -5,37 +5,39 @@ import (
 	"pengi-med-saas/core/auth"
 	"pengi-med-saas/core/envelope"
 	core_errors "pengi-med-saas/core/errors"
+	"pengi-med-saas/core/logger"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(tokens *auth.Tokens) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 1) Verificar que el header Authorization esté presente
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, envelope.ErrorResponse(http.StatusUnauthorized, "Authorization header missing", core_errors.ErrAuthInvalidRequest))
+			envelope.Abort(c, envelope.ErrorResponse(http.StatusUnauthorized, "Authorization header missing", core_errors.ErrAuthInvalidRequest))
 			return
 		}
 
 		// 2) Verificar que tenga el formato "Bearer {token}"
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, envelope.ErrorResponse(http.StatusUnauthorized, "Invalid authorization header format", core_errors.ErrAuthInvalidRequest))
+			envelope.Abort(c, envelope.ErrorResponse(http.StatusUnauthorized, "Invalid authorization header format", core_errors.ErrAuthInvalidRequest))
 			return
 		}
 
 		// 3) Extraer el token
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, envelope.ErrorResponse(http.StatusUnauthorized, "Token is empty", core_errors.ErrAuthInvalidRequest))
+			envelope.Abort(c, envelope.ErrorResponse(http.StatusUnauthorized, "Token is empty", core_errors.ErrAuthInvalidRequest))
 			return
 		}
 
 		// 4) Validar el token
-		claims, err := auth.ParseToken(token)
+		claims, err := tokens.ParseToken(token)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, envelope.ErrorResponse(http.StatusUnauthorized, "Invalid or expired token", core_errors.ErrAuthInvalidRequest))
+			envelope.Abort(c, envelope.ErrorResponse(http.StatusUnauthorized, "Invalid or expired token", core_errors.ErrAuthInvalidRequest))
 			return
 		}
 
@@ -44,13 +46,13 @@ func AuthMiddleware() gin.HandlerFunc {
 		// We expect "userId" (float64) and "username" (string).
 		userID, ok := claims["userId"].(float64)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, envelope.ErrorResponse(http.StatusUnauthorized, "Invalid token payload: userId missing", core_errors.ErrAuthInvalidRequest))
+			envelope.Abort(c, envelope.ErrorResponse(http.StatusUnauthorized, "Invalid token payload: userId missing", core_errors.ErrAuthInvalidRequest))
 			return
 		}
 
 		username, ok := claims["username"].(string)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, envelope.ErrorResponse(http.StatusUnauthorized, "Invalid token payload: username missing", core_errors.ErrAuthInvalidRequest))
+			envelope.Abort(c, envelope.ErrorResponse(http.StatusUnauthorized, "Invalid token payload: username missing", core_errors.ErrAuthInvalidRequest))
 			return
 		}
 
@@ -59,6 +61,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("username", username)
 		c.Set("auth_token", token)
 		c.Set("authenticated", true)
+		c.Request = c.Request.WithContext(logger.With(c.Request.Context(), zap.Int64("user_id", int64(userID))))
 
 		// 7) Continuar con el siguiente middleware/handler
 		c.Next()
@@ -67,7 +70,7 @@ func AuthMiddleware() gin.HandlerFunc {
 
 // OptionalAuthMiddleware es similar a AuthMiddleware pero no bloquea si no hay token
 // Útil para endpoints que pueden funcionar con o sin autenticación
-func OptionalAuthMiddleware() gin.HandlerFunc {
+func OptionalAuthMiddleware(tokens *auth.Tokens) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 
@@ -85,7 +88,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := auth.ParseToken(token)
+		claims, err := tokens.ParseToken(token)
 		if err != nil {
 			// Token inválido, tratamos como no autenticado
 			c.Set("authenticated", false)
@@ -101,6 +104,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			c.Set("username", username)
 			c.Set("auth_token", token)
 			c.Set("authenticated", true)
+			c.Request = c.Request.WithContext(logger.With(c.Request.Context(), zap.Int64("user_id", int64(userID))))
 		} else {
 			c.Set("authenticated", false)
 		}
@@ -0,0 +1,97 @@
+package auth_middleware
+
+import (
+	"net/http"
+	"pengi-med-saas/core/envelope"
+	core_errors "pengi-med-saas/core/errors"
+	company_models "pengi-med-saas/features/companies/models"
+	company_services "pengi-med-saas/features/companies/services"
+	user_services "pengi-med-saas/features/users/services"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+RequirePlatformAdmin lets the request through only when the user is a platform
+admin (User.PlatformAdmin). Company admins are not: what these routes change,
+such as the global messages, is served to every tenant. It goes after
+AuthMiddleware.
+*/
+func RequirePlatformAdmin(users *user_services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authenticatedUserID(c)
+		if !ok {
+			return
+		}
+		admin, err := users.IsPlatformAdmin(c.Request.Context(), userID)
+		if err != nil {
+			envelope.Abort(c, envelope.FromError(err))
+			return
+		}
+		if !admin {
+			envelope.Abort(c, envelope.FromError(core_errors.ErrAuthForbidden))
+			return
+		}
+		c.Next()
+	}
+}
+
+/*
+RequireTenantAdmin lets the request through only when the user holds the
+admin role in a company of the tenant resolved by TenantMiddleware, so a
+valid token and someone else's X-Tenant-Slug are not enough. It goes after
+AuthMiddleware and TenantMiddleware.
+*/
+func RequireTenantAdmin(users *user_services.UserService, companies *company_services.CompanyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ids, ok := adminCompanyIDs(c, users)
+		if !ok {
+			return
+		}
+
+		tenantID := c.GetUint("tenant_id")
+		admin := false
+		if len(ids) > 0 {
+			adminCompanies, err := companies.FindByIDs(c.Request.Context(), ids)
+			if err != nil {
+				envelope.Abort(c, envelope.FromError(err))
+				return
+			}
+			admin = slices.ContainsFunc(adminCompanies, func(company company_models.Company) bool {
+				return company.TenantID == tenantID
+			})
+		}
+		if !admin {
+			envelope.Abort(c, envelope.FromError(core_errors.ErrAuthForbidden))
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticatedUserID returns the user set by AuthMiddleware, aborting the
+// request with 401 when there is none.
+func authenticatedUserID(c *gin.Context) (uint, bool) {
+	userID, _, exists := GetUserFromContext(c)
+	if !exists {
+		envelope.Abort(c, envelope.ErrorResponse(http.StatusUnauthorized, "Authentication required", core_errors.ErrAuthInvalidRequest))
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// adminCompanyIDs returns the companies the authenticated user administers.
+// It aborts the request when there is no user or the lookup fails.
+func adminCompanyIDs(c *gin.Context, users *user_services.UserService) ([]uint, bool) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return nil, false
+	}
+	ids, err := users.AdminCompanyIDs(c.Request.Context(), userID)
+	if err != nil {
+		envelope.Abort(c, envelope.FromError(err))
+		return nil, false
+	}
+	return ids, true
+}
@@ -0,0 +1,99 @@
+package user_repositories
+
+import (
+	"context"
+	"fmt"
+	"pengi-med-saas/core/query"
+	user_models "pengi-med-saas/features/users/models"
+	"sync"
+	"time"
+)
+
+// MemoryUserRepository is an in-memory UserRepository for tests. Like
+// Postgres it assigns IDs and rejects duplicated ones.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	users  map[uint]user_models.User
+	nextID uint
+}
+
+func NewMemoryUserRepository(users ...user_models.User) *MemoryUserRepository {
+	r := &MemoryUserRepository{users: make(map[uint]user_models.User)}
+	for _, user := range users {
+		r.Create(context.Background(), &user)
+	}
+	return r
+}
+
+func (r *MemoryUserRepository) List(ctx context.Context, params query.Params) (query.Page[user_models.User], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]user_models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	return query.Apply(users, params)
+}
+
+func (r *MemoryUserRepository) FindByID(ctx context.Context, id uint) (*user_models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) FindByUserName(ctx context.Context, userName string) (*user_models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.UserName == userName {
+			return &user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user *user_models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.ID == 0 {
+		r.nextID++
+		user.ID = r.nextID
+	} else if _, exists := r.users[user.ID]; exists {
+		return fmt.Errorf("user %d already exists", user.ID)
+	} else if user.ID > r.nextID {
+		r.nextID = user.ID
+	}
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepository) FindEnvironments(ctx context.Context, userID uint) ([]user_models.Environment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]user_models.Environment{}, r.users[userID].Environments...), nil
+}
+
+func (r *MemoryUserRepository) UpdateRefreshToken(ctx context.Context, id uint, refreshToken string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.RefreshToken = refreshToken
+	user.UpdatedAt = time.Now()
+	r.users[id] = user
+	return nil
+}
@@ -0,0 +1,99 @@
+package user_repositories
+
+import (
+	"context"
+	"errors"
+	"pengi-med-saas/core/database"
+	"pengi-med-saas/core/query"
+	user_models "pengi-med-saas/features/users/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// UserQuery is what GET /users lets clients filter and sort on.
+var UserQuery = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.TypeInt, Operators: []query.Operator{query.Eq, query.In}, Sortable: true},
+		"user_name":  {Column: "user_name", Operators: []query.Operator{query.Eq, query.In, query.Like}, Sortable: true},
+		"email":      {Column: "email", Operators: []query.Operator{query.Eq, query.Like}, Sortable: true},
+		"created_at": {Column: "created_at", Type: query.TypeTime, Operators: []query.Operator{query.Gte}, Sortable: true},
+	},
+	DefaultSort: []query.Sort{{Column: "id"}},
+}
+
+type UserRepository interface {
+	List(ctx context.Context, params query.Params) (query.Page[user_models.User], error)
+	FindByID(ctx context.Context, id uint) (*user_models.User, error)
+	FindByUserName(ctx context.Context, userName string) (*user_models.User, error)
+	Create(ctx context.Context, user *user_models.User) error
+	UpdateRefreshToken(ctx context.Context, id uint, refreshToken string) error
+	// FindEnvironments returns the user's environments with their role.
+	FindEnvironments(ctx context.Context, userID uint) ([]user_models.Environment, error)
+}
+
+// GormUserRepository stores users in Postgres. Every method joins the
+// transaction bound to ctx, if any.
+type GormUserRepository struct {
+	db *gorm.DB
+}
+
+func NewGormUserRepository(db *gorm.DB) *GormUserRepository {
+	return &GormUserRepository{db: db}
+}
+
+func (r *GormUserRepository) List(ctx context.Context, params query.Params) (query.Page[user_models.User], error) {
+	return query.Find[user_models.User](database.FromContext(ctx, r.db), params)
+}
+
+func (r *GormUserRepository) FindByID(ctx context.Context, id uint) (*user_models.User, error) {
+	var user user_models.User
+	if err := database.FromContext(ctx, r.db).First(&user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *GormUserRepository) FindByUserName(ctx context.Context, userName string) (*user_models.User, error) {
+	var user user_models.User
+	if err := database.FromContext(ctx, r.db).Where("user_name = ?", userName).First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *GormUserRepository) Create(ctx context.Context, user *user_models.User) error {
+	return database.FromContext(ctx, r.db).Create(user).Error
+}
+
+func (r *GormUserRepository) UpdateRefreshToken(ctx context.Context, id uint, refreshToken string) error {
+	result := database.FromContext(ctx, r.db).
+		Model(&user_models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"refresh_token": refreshToken,
+			"updated_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *GormUserRepository) FindEnvironments(ctx context.Context, userID uint) ([]user_models.Environment, error) {
+	environments := []user_models.Environment{}
+	err := database.FromContext(ctx, r.db).Preload("Role").Where("user_id = ?", userID).Order("id").Find(&environments).Error
+	return environments, err
+}
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
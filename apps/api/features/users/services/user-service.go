@@ -0,0 +1,95 @@
+package user_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pengi-med-saas/core/auth"
+	"pengi-med-saas/core/query"
+	user_models "pengi-med-saas/features/users/models"
+	user_repositories "pengi-med-saas/features/users/repositories"
+)
+
+var ErrInvalidCredentials = errors.New("incorrect username or password")
+
+type UserService struct {
+	users user_repositories.UserRepository
+}
+
+func NewUserService(users user_repositories.UserRepository) *UserService {
+	return &UserService{users: users}
+}
+
+func (s *UserService) List(ctx context.Context, params query.Params) (query.Page[user_models.User], error) {
+	return s.users.List(ctx, params)
+}
+
+func (s *UserService) FindByID(ctx context.Context, id uint) (*user_models.User, error) {
+	return s.users.FindByID(ctx, id)
+}
+
+// SignUp hashes the user's password and stores the user.
+func (s *UserService) SignUp(ctx context.Context, user *user_models.User) error {
+	hashPassword, err := auth.HashPassword(user.Password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = hashPassword
+
+	if err := s.users.Create(ctx, user); err != nil {
+		return fmt.Errorf("failed to create user record: %w", err)
+	}
+	return nil
+}
+
+// Authenticate returns the user matching the credentials, or
+// ErrInvalidCredentials without revealing which part was wrong.
+func (s *UserService) Authenticate(ctx context.Context, userName, password string) (*user_models.User, error) {
+	user, err := s.users.FindByUserName(ctx, userName)
+	if err != nil {
+		if errors.Is(err, user_repositories.ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to retrieve user record: %w", err)
+	}
+
+	if !auth.CompareHashAndPassword(user.Password, password) {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// IsPlatformAdmin reports whether the user administers the whole platform.
+func (s *UserService) IsPlatformAdmin(ctx context.Context, userID uint) (bool, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if errors.Is(err, user_repositories.ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve user record: %w", err)
+	}
+	return user.PlatformAdmin, nil
+}
+
+// AdminCompanyIDs returns the companies in which the user holds RoleAdmin.
+func (s *UserService) AdminCompanyIDs(ctx context.Context, userID uint) ([]uint, error) {
+	environments, err := s.users.FindEnvironments(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user environments: %w", err)
+	}
+	var ids []uint
+	for _, environment := range environments {
+		if environment.Role.Role == user_models.RoleAdmin {
+			ids = append(ids, environment.CompanyID)
+		}
+	}
+	return ids, nil
+}
+
+func (s *UserService) UpdateRefreshToken(ctx context.Context, user *user_models.User, refreshToken string) error {
+	if err := s.users.UpdateRefreshToken(ctx, user.ID, refreshToken); err != nil {
+		return fmt.Errorf("failed to update refresh token: %w", err)
+	}
+	user.RefreshToken = refreshToken
+	return nil
+}
@@ -1,65 +1,180 @@
 package message_cache
 
 import (
+	"context"
+	"pengi-med-saas/core/logger"
+	message_format "pengi-med-saas/i18n/messageformat"
 	message_models "pengi-med-saas/i18n/models"
+	"sort"
 	"sync"
 
-	"gorm.io/gorm"
+	"go.uber.org/zap"
 )
 
+// Loader provides the messages the cache is filled with.
+type Loader interface {
+	List(ctx context.Context) ([]message_models.Message, error)
+}
+
+type translations map[string]map[string]string // lang -> key -> value
+
 var (
-	cache map[string]map[string]string // lang -> key -> value
-	mutex sync.RWMutex
-	once  sync.Once
+	cache     translations
+	overrides map[uint]translations // tenant -> overrides
+	mutex     sync.RWMutex
+	once      sync.Once
+	// loaded is set by the first successful load.
+	loadedOnce bool
+
+	// fallbackLang is tried when a message is missing in the requested language.
+	fallbackLang = "es"
 )
 
-func Init(db *gorm.DB) error {
+func Init(repo Loader) error {
 	var err error
 	once.Do(func() {
-		cache = make(map[string]map[string]string)
-		err = loadMessages(db)
+		cache = make(translations)
+		err = loadMessages(repo)
 	})
 	return err
 }
 
-func loadMessages(db *gorm.DB) error {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	var messages []message_models.Message
-	if err := db.Find(&messages).Error; err != nil {
+// loadMessages replaces the cache with the repository contents, so deleted
+// messages disappear on reload.
+func loadMessages(repo Loader) error {
+	messages, err := repo.List(context.Background())
+	if err != nil {
 		return err
 	}
 
+	loaded := make(translations)
+	loadedOverrides := make(map[uint]translations)
 	for _, msg := range messages {
-		if cache[msg.Lang] == nil {
-			cache[msg.Lang] = make(map[string]string)
+		target := loaded
+		if msg.TenantID != nil {
+			if loadedOverrides[*msg.TenantID] == nil {
+				loadedOverrides[*msg.TenantID] = make(translations)
+			}
+			target = loadedOverrides[*msg.TenantID]
 		}
-		cache[msg.Lang][msg.Key] = msg.Value
+		target.set(msg.Lang, msg.Key, msg.Value)
 	}
+
+	mutex.Lock()
+	cache = loaded
+	overrides = loadedOverrides
+	record(loaded, loadedOverrides)
+	loadedOnce = true
+	mutex.Unlock()
 	return nil
 }
 
-func Get(lang, key string) string {
+func (t translations) set(lang, key, value string) {
+	if t[lang] == nil {
+		t[lang] = make(map[string]string)
+	}
+	t[lang][key] = value
+}
+
+func (t translations) get(lang, key string) (string, bool) {
+	val, ok := t[lang][key]
+	return val, ok
+}
+
+/*
+Get returns the translation of key, trying in order the tenant's override
+and the global message in lang, then the same in the fallback language. It
+returns the key itself when nothing matches. tenantID 0 skips overrides.
+*/
+func Get(tenantID uint, lang, key string) string {
+	if val, _, ok := lookup(tenantID, lang, key); ok {
+		return val
+	}
+	return key
+}
+
+/*
+Format is Get followed by ICU MessageFormat formatting with args, using the
+plural rules and number formats of the language the message was found in.
+A message that fails to format is returned unformatted and logged, so a bad
+translation never breaks a response.
+*/
+func Format(tenantID uint, lang, key string, args map[string]any) string {
+	val, foundLang, ok := lookup(tenantID, lang, key)
+	if !ok {
+		return key
+	}
+	return format(key, val, foundLang, args)
+}
+
+func format(key, val, foundLang string, args map[string]any) string {
+	formatted, err := message_format.Format(foundLang, val, args)
+	if err != nil {
+		logger.Warn("Failed to format message",
+			zap.String("key", key),
+			zap.String("lang", foundLang),
+			zap.Error(err),
+		)
+		return val
+	}
+	return formatted
+}
+
+func lookup(tenantID uint, lang, key string) (string, string, bool) {
 	mutex.RLock()
 	defer mutex.RUnlock()
 
-	if cache[lang] != nil {
-		if val, ok := cache[lang][key]; ok {
-			return val
+	langs := []string{lang}
+	if lang != fallbackLang {
+		langs = append(langs, fallbackLang)
+	}
+	for i, l := range langs {
+		if tenantID != 0 {
+			if val, ok := overrides[tenantID].get(l, key); ok {
+				recordLookup(key, hitAttributes)
+				return val, l, true
+			}
+		}
+		if val, ok := cache.get(l, key); ok {
+			recordLookup(key, hitAttributes)
+			return val, l, true
+		}
+		if i == 0 {
+			recordMissing(lang, key)
 		}
 	}
+	recordLookup(key, missAttributes)
+	return "", "", false
+}
 
-	// Fallback to Spanish or default key if needed
-	if lang != "es" && cache["es"] != nil {
-		if val, ok := cache["es"][key]; ok {
-			return val
-		}
+// SetFallbackLang changes the language tried when a message is missing.
+func SetFallbackLang(lang string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	fallbackLang = lang
+}
+
+// Languages returns the languages that have global messages, sorted.
+func Languages() []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	langs := make([]string, 0, len(cache))
+	for lang := range cache {
+		langs = append(langs, lang)
 	}
+	sort.Strings(langs)
+	return langs
+}
 
-	return key
+func Reload(repo Loader) error {
+	return loadMessages(repo)
 }
 
-func Reload(db *gorm.DB) error {
-	return loadMessages(db)
+// Loaded reports whether messages were loaded at least once; until then
+// every lookup falls back to the key.
+func Loaded() bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return loadedOnce
 }
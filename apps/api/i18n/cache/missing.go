@@ -0,0 +1,130 @@
+package message_cache
+
+import (
+	"pengi-med-saas/core/logger"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxMissing bounds the report so a misbehaving caller cannot grow it forever.
+const maxMissing = 10000
+
+// MissingTranslation is a key that was looked up in a language without a
+// message for it, since the process started.
+type MissingTranslation struct {
+	Lang      string    `json:"lang"`
+	Key       string    `json:"key"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type missingKey struct {
+	lang string
+	key  string
+}
+
+var (
+	missing      = make(map[missingKey]*MissingTranslation)
+	missingMutex sync.Mutex
+)
+
+/*
+looksLikeKey reports whether key is shaped like a message key (no whitespace).
+Plain text such as "Messages obtained successfully" is also passed through the
+translator and must not count as a lookup or a missing translation.
+*/
+func looksLikeKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key, " \t\n")
+}
+
+/*
+recordMissing notes a lookup that missed in lang, whether or not the fallback
+language had the key. Only strings that look like keys count, and languages
+without any message are ignored. Callers hold mutex.
+*/
+func recordMissing(lang, key string) {
+	if !looksLikeKey(key) {
+		return
+	}
+	if _, ok := cache[lang]; !ok {
+		return
+	}
+
+	missingMutex.Lock()
+	defer missingMutex.Unlock()
+
+	now := time.Now()
+	id := missingKey{lang, key}
+	if entry, ok := missing[id]; ok {
+		entry.Count++
+		entry.LastSeen = now
+		return
+	}
+	if len(missing) >= maxMissing {
+		return
+	}
+
+	missing[id] = &MissingTranslation{Lang: lang, Key: key, Count: 1, FirstSeen: now, LastSeen: now}
+	logger.Warn("Missing translation", zap.String("lang", lang), zap.String("key", key))
+}
+
+// MissingTranslations returns the recorded misses, most frequent first.
+func MissingTranslations() []MissingTranslation {
+	missingMutex.Lock()
+	defer missingMutex.Unlock()
+
+	report := make([]MissingTranslation, 0, len(missing))
+	for _, entry := range missing {
+		report = append(report, *entry)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Count != report[j].Count {
+			return report[i].Count > report[j].Count
+		}
+		if report[i].Lang != report[j].Lang {
+			return report[i].Lang < report[j].Lang
+		}
+		return report[i].Key < report[j].Key
+	})
+	return report
+}
+
+// LanguageCoverage is how many of the known global keys a language translates.
+type LanguageCoverage struct {
+	Lang       string   `json:"lang"`
+	Translated int      `json:"translated"`
+	Total      int      `json:"total"`
+	Missing    []string `json:"missing"`
+}
+
+// Coverage compares every language against the union of the global keys.
+func Coverage() []LanguageCoverage {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	keys := make(map[string]struct{})
+	for _, messages := range cache {
+		for key := range messages {
+			keys[key] = struct{}{}
+		}
+	}
+
+	report := make([]LanguageCoverage, 0, len(cache))
+	for lang, messages := range cache {
+		coverage := LanguageCoverage{Lang: lang, Translated: len(messages), Total: len(keys), Missing: []string{}}
+		for key := range keys {
+			if _, ok := messages[key]; !ok {
+				coverage.Missing = append(coverage.Missing, key)
+			}
+		}
+		sort.Strings(coverage.Missing)
+		report = append(report, coverage)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Lang < report[j].Lang })
+	return report
+}
@@ -0,0 +1,134 @@
+package message_cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"pengi-med-saas/core/database"
+	"pengi-med-saas/core/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// notifyChannel is the Postgres channel that carries cache invalidations.
+const notifyChannel = "i18n_messages"
+
+// instanceID tags this process's notifications so it can skip its own.
+var instanceID = newInstanceID()
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+/*
+Sync keeps the message cache of every API instance up to date.
+
+Invalidate reloads the local cache after a write and publishes a Postgres
+NOTIFY; Listen, running on every instance, reloads the cache when another
+instance publishes one. The loader should read from the primary: reloads
+react to writes a lagging replica may not have yet. With a nil db only the
+local cache is reloaded, which is what tests with in-memory repositories need.
+*/
+type Sync struct {
+	db     *gorm.DB
+	loader Loader
+}
+
+func NewSync(db *gorm.DB, loader Loader) *Sync {
+	return &Sync{db: db, loader: loader}
+}
+
+// Init fills the cache from the loader the first time it is called.
+func (s *Sync) Init() error {
+	return Init(s.loader)
+}
+
+// Invalidate reloads the local cache and tells the other instances to reload theirs.
+func (s *Sync) Invalidate(ctx context.Context) error {
+	if err := Reload(s.loader); err != nil {
+		return fmt.Errorf("failed to reload messages: %w", err)
+	}
+	if s.db == nil {
+		return nil
+	}
+	// A hot standby rejects NOTIFY, and dbresolver would send this SELECT to one.
+	if err := database.Primary(s.db).WithContext(ctx).Exec("SELECT pg_notify(?, ?)", notifyChannel, instanceID).Error; err != nil {
+		return fmt.Errorf("failed to notify other instances: %w", err)
+	}
+	return nil
+}
+
+// Listen reloads the cache whenever another instance changes messages. It
+// blocks until ctx is done, reconnecting with backoff when the connection drops.
+func (s *Sync) Listen(ctx context.Context) {
+	if s.db == nil {
+		return
+	}
+
+	backoff := time.Second
+	for {
+		err := s.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.Warn("i18n cache listener disconnected, retrying",
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+func (s *Sync) listen(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("LISTEN needs a pgx connection, got %T", driverConn)
+		}
+		pgxConn := stdlibConn.Conn()
+
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+			return err
+		}
+		// Changes made while the listener was down were never announced.
+		if err := Reload(s.loader); err != nil {
+			logger.Error("Failed to reload messages", zap.Error(err))
+		}
+		logger.Debug("Listening for i18n cache invalidations", zap.String("channel", notifyChannel))
+
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			if notification.Payload == instanceID {
+				continue
+			}
+			if err := Reload(s.loader); err != nil {
+				logger.Error("Failed to reload messages", zap.Error(err))
+			}
+		}
+	})
+}
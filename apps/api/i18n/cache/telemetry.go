@@ -0,0 +1,49 @@
+package message_cache
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "pengi-med-saas/i18n/cache"
+
+var (
+	tracer = otel.Tracer(instrumentationName)
+
+	// lookups counts cache lookups of keys by result; misses are keys without
+	// a message in the requested or the fallback language.
+	lookups, _ = otel.Meter(instrumentationName).Int64Counter("i18n.cache.lookups",
+		metric.WithDescription("Message cache lookups by result (hit or miss)."))
+
+	hitAttributes  = metric.WithAttributeSet(attribute.NewSet(attribute.String("result", "hit")))
+	missAttributes = metric.WithAttributeSet(attribute.NewSet(attribute.String("result", "miss")))
+)
+
+// recordLookup counts a lookup of key, skipping plain text (see looksLikeKey).
+func recordLookup(key string, result metric.AddOption) {
+	if looksLikeKey(key) {
+		lookups.Add(context.Background(), 1, result)
+	}
+}
+
+// FormatContext is Format recorded as a span of the request in ctx.
+func FormatContext(ctx context.Context, tenantID uint, lang, key string, args map[string]any) string {
+	_, span := tracer.Start(ctx, "i18n.cache.lookup", trace.WithAttributes(
+		attribute.String("i18n.key", key),
+		attribute.String("i18n.lang", lang),
+		attribute.Int64("tenant.id", int64(tenantID)),
+	))
+	defer span.End()
+
+	val, foundLang, ok := lookup(tenantID, lang, key)
+	span.SetAttributes(attribute.Bool("i18n.cache.hit", ok))
+	if !ok {
+		return key
+	}
+	span.SetAttributes(attribute.String("i18n.found_lang", foundLang))
+	return format(key, val, foundLang, args)
+}
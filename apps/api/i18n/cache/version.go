@@ -0,0 +1,164 @@
+package message_cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+// maxHistory is how many reloads back a client can ask for a delta; older
+// versions get the full message set.
+const maxHistory = 32
+
+// generation is the cache contents after one reload. Its maps are never
+// modified after the swap, so old generations can be diffed safely.
+type generation struct {
+	global    translations
+	overrides map[uint]translations
+
+	mu       sync.Mutex
+	versions map[versionKey]string
+}
+
+type versionKey struct {
+	tenantID uint
+	lang     string
+}
+
+// history holds the latest generations, oldest first. Guarded by mutex.
+var history []*generation
+
+func record(global translations, overrides map[uint]translations) {
+	history = append(history, &generation{
+		global:    global,
+		overrides: overrides,
+		versions:  make(map[versionKey]string),
+	})
+	if len(history) > maxHistory {
+		history = append([]*generation(nil), history[len(history)-maxHistory:]...)
+	}
+}
+
+// snapshot returns the messages a tenant sees in lang: the global messages
+// with the tenant's overrides applied, as served by GET /i18n/messages.
+func (g *generation) snapshot(tenantID uint, lang string) map[string]string {
+	messages := make(map[string]string, len(g.global[lang]))
+	for key, value := range g.global[lang] {
+		messages[key] = value
+	}
+	if tenantID != 0 {
+		for key, value := range g.overrides[tenantID][lang] {
+			messages[key] = value
+		}
+	}
+	return messages
+}
+
+/*
+version hashes the snapshot, so every replica that loaded the same messages
+reports the same version and a client can revalidate against any of them.
+*/
+func (g *generation) version(tenantID uint, lang string) string {
+	key := versionKey{tenantID, lang}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if version, ok := g.versions[key]; ok {
+		return version
+	}
+
+	version := VersionOf(g.snapshot(tenantID, lang))
+	g.versions[key] = version
+	return version
+}
+
+// VersionOf returns the version of a key -> value message set, as Version
+// reports it for the cached messages.
+func VersionOf(messages map[string]string) string {
+	keys := make([]string, 0, len(messages))
+	for k := range messages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, k := range keys {
+		hash.Write([]byte(k))
+		hash.Write([]byte{0})
+		hash.Write([]byte(messages[k]))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
+
+func latest() *generation {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if len(history) == 0 {
+		return &generation{versions: make(map[versionKey]string)}
+	}
+	return history[len(history)-1]
+}
+
+// Version returns the content version of the messages a tenant sees in lang.
+// tenantID 0 versions the global messages.
+func Version(tenantID uint, lang string) string {
+	return latest().version(tenantID, lang)
+}
+
+// Delta is the difference between a client's version and the current one.
+type Delta struct {
+	Version string `json:"version"`
+	// Full is set when the client's version is unknown (too old, or never
+	// served by this replica) and Messages holds every message.
+	Full     bool              `json:"full"`
+	Messages map[string]string `json:"messages"`
+	Removed  []string          `json:"removed"`
+}
+
+/*
+Changes returns the messages added or changed in lang since the version the
+client has, and the keys removed since then.
+*/
+func Changes(tenantID uint, lang, since string) Delta {
+	mutex.RLock()
+	generations := append([]*generation(nil), history...)
+	mutex.RUnlock()
+	if len(generations) == 0 {
+		generations = []*generation{latest()}
+	}
+
+	current := generations[len(generations)-1]
+	delta := Delta{
+		Version:  current.version(tenantID, lang),
+		Messages: make(map[string]string),
+		Removed:  []string{},
+	}
+	now := current.snapshot(tenantID, lang)
+
+	var previous map[string]string
+	for i := len(generations) - 1; i >= 0 && previous == nil; i-- {
+		if generations[i].version(tenantID, lang) == since {
+			previous = generations[i].snapshot(tenantID, lang)
+		}
+	}
+	if previous == nil {
+		delta.Full = true
+		delta.Messages = now
+		return delta
+	}
+
+	for key, value := range now {
+		if old, ok := previous[key]; !ok || old != value {
+			delta.Messages[key] = value
+		}
+	}
+	for key := range previous {
+		if _, ok := now[key]; !ok {
+			delta.Removed = append(delta.Removed, key)
+		}
+	}
+	sort.Strings(delta.Removed)
+	return delta
+}
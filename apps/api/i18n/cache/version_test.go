@@ -0,0 +1,107 @@
+package message_cache
+
+import (
+	"context"
+	message_models "pengi-med-saas/i18n/models"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type loaderFunc func() []message_models.Message
+
+func (f loaderFunc) List(context.Context) ([]message_models.Message, error) {
+	return f(), nil
+}
+
+// load reloads the cache with messages and returns the version tenantID sees in lang.
+func load(t *testing.T, tenantID uint, lang string, messages ...message_models.Message) string {
+	t.Helper()
+	if err := Reload(loaderFunc(func() []message_models.Message { return messages })); err != nil {
+		t.Fatal(err)
+	}
+	return Version(tenantID, lang)
+}
+
+func override(tenantID uint, key, value string) message_models.Message {
+	message := *message_models.NewMessage(key, value, "es")
+	message.TenantID = &tenantID
+	return message
+}
+
+func TestChanges(t *testing.T) {
+	hello := *message_models.NewMessage("hello", "Hola", "es")
+	bye := *message_models.NewMessage("bye", "Adiós", "es")
+	thanks := *message_models.NewMessage("thanks", "Gracias", "es")
+
+	v1 := load(t, 0, "es", hello, bye)
+	v2 := load(t, 0, "es", *message_models.NewMessage("hello", "Buenas", "es"), thanks)
+
+	tests := []struct {
+		name  string
+		since string
+		want  Delta
+	}{
+		{"current version", v2, Delta{Version: v2, Messages: map[string]string{}, Removed: []string{}}},
+		{"previous version", v1, Delta{
+			Version:  v2,
+			Messages: map[string]string{"hello": "Buenas", "thanks": "Gracias"},
+			Removed:  []string{"bye"},
+		}},
+		{"unknown version", "nope", Delta{
+			Version:  v2,
+			Full:     true,
+			Messages: map[string]string{"hello": "Buenas", "thanks": "Gracias"},
+			Removed:  []string{},
+		}},
+		{"no version", "", Delta{
+			Version:  v2,
+			Full:     true,
+			Messages: map[string]string{"hello": "Buenas", "thanks": "Gracias"},
+			Removed:  []string{},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Changes(0, "es", tt.since); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Changes(%q) = %+v, want %+v", tt.since, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangesWithOverrides(t *testing.T) {
+	hello := *message_models.NewMessage("hello", "Hola", "es")
+
+	v1 := load(t, 7, "es", hello)
+	v2 := load(t, 7, "es", hello, override(7, "hello", "Bienvenido"), override(8, "hello", "Saludos"))
+	if v1 == v2 {
+		t.Fatal("an override did not change the tenant's version")
+	}
+
+	got := Changes(7, "es", v1)
+	want := map[string]string{"hello": "Bienvenido"}
+	if got.Full || !reflect.DeepEqual(got.Messages, want) {
+		t.Errorf("Changes = %+v, want messages %v", got, want)
+	}
+	if global := Changes(0, "es", Version(0, "es")); len(global.Messages) != 0 {
+		t.Errorf("global messages changed: %+v", global)
+	}
+}
+
+func TestChangesOutOfHistory(t *testing.T) {
+	first := load(t, 0, "es", *message_models.NewMessage("n", "0", "es"))
+	for i := 1; i < maxHistory; i++ {
+		load(t, 0, "es", *message_models.NewMessage("n", strconv.Itoa(i), "es"))
+	}
+	if delta := Changes(0, "es", first); delta.Full {
+		t.Fatalf("version %d reloads back answered with the full set", maxHistory-1)
+	}
+
+	current := load(t, 0, "es", *message_models.NewMessage("n", "last", "es"))
+	delta := Changes(0, "es", first)
+	if !delta.Full || delta.Version != current || delta.Messages["n"] != "last" {
+		t.Errorf("Changes of an evicted version = %+v, want the full set at %s", delta, current)
+	}
+}
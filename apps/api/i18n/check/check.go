@@ -0,0 +1,213 @@
+package message_check
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+keyArguments lists the calls whose string literal argument is a message key,
+by function name and argument index. A package qualifier, when given, must
+match the import name the repo uses for it. String literals assigned to a
+MessageKey field (core_errors.FieldError) are keys too.
+*/
+var keyArguments = []struct {
+	pkg  string
+	name string
+	arg  int
+}{
+	{"core_errors", "NewAppError", 0},
+	{"core_errors", "Register", 0},
+	{"message_cache", "Get", 2},
+	{"message_cache", "Format", 2},
+}
+
+// Reference is a message key used in Go code.
+type Reference struct {
+	Key      string
+	Position token.Position
+}
+
+// Missing is a key a language file lacks.
+type Missing struct {
+	Lang string
+	Key  string
+	// Source explains where the key is expected from: a code reference or
+	// another language file.
+	Source string
+}
+
+/*
+Check scans the Go files under root for message keys and compares them, and
+the keys of every i18n/messages/messages_<lang>.json file, against each
+language file. It returns what each language lacks, sorted by language and key.
+*/
+func Check(root string) ([]Missing, error) {
+	references, err := FindReferences(root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := LoadMessageFiles(filepath.Join(root, "i18n", "messages"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no messages_<lang>.json files in %s", filepath.Join(root, "i18n", "messages"))
+	}
+
+	required := make(map[string]string) // key -> source
+	for _, ref := range references {
+		if _, ok := required[ref.Key]; !ok {
+			required[ref.Key] = ref.Position.String()
+		}
+	}
+	langs := make([]string, 0, len(files))
+	for lang := range files {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		for key := range files[lang] {
+			if _, ok := required[key]; !ok {
+				required[key] = fmt.Sprintf("messages_%s.json", lang)
+			}
+		}
+	}
+
+	var missing []Missing
+	for _, lang := range langs {
+		for key, source := range required {
+			if _, ok := files[lang][key]; !ok {
+				missing = append(missing, Missing{Lang: lang, Key: key, Source: source})
+			}
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Lang != missing[j].Lang {
+			return missing[i].Lang < missing[j].Lang
+		}
+		return missing[i].Key < missing[j].Key
+	})
+	return missing, nil
+}
+
+// FindReferences returns the message keys passed as literals to the calls in
+// keyArguments, skipping tests, vendored code and hidden directories.
+func FindReferences(root string) ([]Reference, error) {
+	var references []Reference
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				if ref, ok := keyReference(fset, file.Name.Name, n); ok {
+					references = append(references, ref)
+				}
+			case *ast.KeyValueExpr:
+				if ident, ok := n.Key.(*ast.Ident); ok && ident.Name == "MessageKey" {
+					if ref, ok := literalReference(fset, n.Value); ok {
+						references = append(references, ref)
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	return references, err
+}
+
+func keyReference(fset *token.FileSet, pkg string, call *ast.CallExpr) (Reference, bool) {
+	var qualifier, name string
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		qualifier, name = pkg, fn.Name
+	case *ast.SelectorExpr:
+		ident, ok := fn.X.(*ast.Ident)
+		if !ok {
+			return Reference{}, false
+		}
+		qualifier, name = ident.Name, fn.Sel.Name
+	default:
+		return Reference{}, false
+	}
+
+	for _, candidate := range keyArguments {
+		if candidate.pkg != qualifier || candidate.name != name || candidate.arg >= len(call.Args) {
+			continue
+		}
+		return literalReference(fset, call.Args[candidate.arg])
+	}
+	return Reference{}, false
+}
+
+func literalReference(fset *token.FileSet, expr ast.Expr) (Reference, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return Reference{}, false
+	}
+	key, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return Reference{}, false
+	}
+	return Reference{Key: key, Position: fset.Position(lit.Pos())}, true
+}
+
+// LoadMessageFiles reads every messages_<lang>.json in dir into lang -> key -> value.
+func LoadMessageFiles(dir string) (map[string]map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "messages_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]map[string]string, len(paths))
+	for _, path := range paths {
+		lang := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "messages_"), ".json")
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var entries []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		files[lang] = make(map[string]string, len(entries))
+		for _, entry := range entries {
+			files[lang][entry.Key] = entry.Value
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,48 @@
+package message_check
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+)
+
+const commandUsage = `Usage: main i18n check [--root DIR]
+
+Compares the message keys used in Go code (core_errors codes and message_cache
+lookups) and the keys of every i18n/messages/messages_<lang>.json file against
+each language file, and fails when a language lacks a key.
+
+Flags:
+  --root DIR  module directory to scan (default ".")
+`
+
+// RunCommand runs an i18n subcommand, writing its output to out.
+func RunCommand(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprint(out, commandUsage)
+		return errors.New("missing i18n command")
+	}
+
+	flags := flag.NewFlagSet("i18n check", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() { fmt.Fprint(out, commandUsage) }
+	root := flags.String("root", ".", "")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	missing, err := Check(*root)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		fmt.Fprintln(out, "All message keys are translated in every language.")
+		return nil
+	}
+
+	for _, m := range missing {
+		fmt.Fprintf(out, "messages_%s.json: missing %q (from %s)\n", m.Lang, m.Key, m.Source)
+	}
+	return fmt.Errorf("%d missing translations", len(missing))
+}
@@ -1,38 +1,92 @@
 package i18n_handlers
 
 import (
-	"net/http"
 	"pengi-med-saas/core/envelope"
-	core_errors "pengi-med-saas/core/errors"
-	message_models "pengi-med-saas/i18n/models"
+	message_cache "pengi-med-saas/i18n/cache"
+	message_services "pengi-med-saas/i18n/services"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type MessageHandler struct {
-	db *gorm.DB
+	service *message_services.MessageService
 }
 
-func NewMessageHandler(db *gorm.DB) *MessageHandler {
-	return &MessageHandler{db: db}
+func NewMessageHandler(service *message_services.MessageService) *MessageHandler {
+	return &MessageHandler{service: service}
 }
 
+/*
+GetAllMessages lists the messages of the request language. The response
+carries an ETag with the version of the messages returned, and a request
+whose If-None-Match matches it gets 304.
+
+The ETag always hashes the rows in the body, never the cache: until a reload
+reaches this instance, or while a replica lags, the two can differ, and a
+client must never store content under another content's version.
+*/
 func (h *MessageHandler) GetAllMessages(c *gin.Context) envelope.Response {
-	lang := c.Query("lang")
-	if lang == "" {
-		lang = "es" // Default language
-	}
+	tenantID, lang := c.GetUint("tenant_id"), requestLang(c)
 
-	messages := []message_models.Message{}
-	if err := h.db.Where("lang = ?", lang).Find(&messages).Error; err != nil {
-		return envelope.ErrorResponse(http.StatusInternalServerError, "Error obtaining messages", core_errors.ErrMessagesNotFound)
+	messages, err := h.service.ListForTenant(c.Request.Context(), tenantID, lang)
+	if err != nil {
+		return envelope.FromError(err)
 	}
 
+	values := make(map[string]string, len(messages))
+	for _, message := range messages {
+		values[message.Key] = message.Value
+	}
+	if etag := setVersionHeaders(c, message_cache.VersionOf(values)); etagMatches(c.GetHeader("If-None-Match"), etag) {
+		return envelope.NotModified()
+	}
 	return envelope.SuccessResponse(messages, "Messages obtained successfully")
 }
 
+// GetMessageChanges returns the keys changed and removed since the version
+// in ?since=, or every message when that version is unknown.
+func (h *MessageHandler) GetMessageChanges(c *gin.Context) envelope.Response {
+	tenantID, lang := c.GetUint("tenant_id"), requestLang(c)
+
+	since := strings.Trim(c.Query("since"), `"`)
+	delta := message_cache.Changes(tenantID, lang, since)
+	setVersionHeaders(c, delta.Version)
+
+	return envelope.SuccessResponse(delta, "Messages obtained successfully")
+}
+
 func (h *MessageHandler) GetMessageVersion(c *gin.Context) envelope.Response {
-	version := "v0.1.0"
+	version := message_cache.Version(c.GetUint("tenant_id"), requestLang(c))
+	setVersionHeaders(c, version)
 	return envelope.SuccessResponse(version, "Version obtained successfully")
 }
+
+func requestLang(c *gin.Context) string {
+	// Negotiated by I18nMiddleware from ?lang= and Accept-Language.
+	if lang := c.GetString("lang"); lang != "" {
+		return lang
+	}
+	return "es" // Default language
+}
+
+// setVersionHeaders sets the ETag for version and returns it. Clients must
+// revalidate, and caches must key on the headers that pick tenant and language.
+func setVersionHeaders(c *gin.Context, version string) string {
+	etag := `"` + version + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Vary", "Accept-Language, X-Tenant-Slug")
+	return etag
+}
+
+// etagMatches implements the weak comparison If-None-Match uses.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package i18n_handlers
+
+import "testing"
+
+func TestEtagMatches(t *testing.T) {
+	const etag = `"abc123"`
+
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{``, false},
+		{`"abc123"`, true},
+		{`W/"abc123"`, true},
+		{`"other"`, false},
+		{`abc123`, false},
+		{`"other", "abc123"`, true},
+		{`"other",W/"abc123"`, true},
+		{`"other", "more"`, false},
+		{`*`, true},
+		{`"abc1234"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, etag); got != tt.want {
+			t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.header, etag, got, tt.want)
+		}
+	}
+}
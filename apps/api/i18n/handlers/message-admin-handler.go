@@ -0,0 +1,160 @@
+package i18n_handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"pengi-med-saas/core/envelope"
+	core_errors "pengi-med-saas/core/errors"
+	"pengi-med-saas/core/query"
+	"pengi-med-saas/core/validation"
+	message_cache "pengi-med-saas/i18n/cache"
+	message_models "pengi-med-saas/i18n/models"
+	message_repositories "pengi-med-saas/i18n/repositories"
+	message_services "pengi-med-saas/i18n/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type messageRequest struct {
+	Key   string `json:"key" binding:"required"`
+	Value string `json:"value" binding:"required"`
+	Lang  string `json:"lang" binding:"required"`
+}
+
+// ListMessages pages through the global messages; see MessageQuery for filters.
+func (h *MessageHandler) ListMessages(c *gin.Context) envelope.Response {
+	params, err := query.Parse(c.Request.URL.Query(), message_repositories.MessageQuery)
+	if err != nil {
+		return envelope.InvalidQuery(err)
+	}
+
+	messages, err := h.service.Search(c.Request.Context(), params)
+	if err != nil {
+		return envelope.FromError(err)
+	}
+	return envelope.Paginated(messages, "Messages obtained successfully")
+}
+
+type missingReport struct {
+	Missing  []message_cache.MissingTranslation `json:"missing"`
+	Coverage []message_cache.LanguageCoverage   `json:"coverage"`
+}
+
+/*
+GetMissingMessages reports the keys looked up without a translation since this
+instance started, and how many of the global keys each language translates.
+Each replica keeps its own counts.
+*/
+func (h *MessageHandler) GetMissingMessages(c *gin.Context) envelope.Response {
+	report := missingReport{
+		Missing:  message_cache.MissingTranslations(),
+		Coverage: message_cache.Coverage(),
+	}
+	return envelope.SuccessResponse(report, "Messages obtained successfully")
+}
+
+func (h *MessageHandler) CreateMessage(c *gin.Context) envelope.Response {
+	var req messageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return envelope.ErrorResponse(http.StatusBadRequest, "Invalid message request",
+			core_errors.ErrMessageInvalidRequest.WithDetails(validation.Details(err)))
+	}
+
+	message := message_models.NewMessage(req.Key, req.Value, req.Lang)
+	if err := h.service.Create(c.Request.Context(), message); err != nil {
+		return messageErrorResponse(err)
+	}
+	return envelope.New(http.StatusCreated, "Message created successfully", message)
+}
+
+func (h *MessageHandler) UpdateMessage(c *gin.Context) envelope.Response {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return envelope.ErrorResponse(http.StatusBadRequest, "Invalid message ID", core_errors.ErrMessageInvalidRequest)
+	}
+	var req messageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return envelope.ErrorResponse(http.StatusBadRequest, "Invalid message request",
+			core_errors.ErrMessageInvalidRequest.WithDetails(validation.Details(err)))
+	}
+
+	message, err := h.service.Update(c.Request.Context(), uint(id), *message_models.NewMessage(req.Key, req.Value, req.Lang))
+	if err != nil {
+		return messageErrorResponse(err)
+	}
+	return envelope.SuccessResponse(message, "Message updated successfully")
+}
+
+func (h *MessageHandler) DeleteMessage(c *gin.Context) envelope.Response {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return envelope.ErrorResponse(http.StatusBadRequest, "Invalid message ID", core_errors.ErrMessageInvalidRequest)
+	}
+	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
+		return messageErrorResponse(err)
+	}
+	return envelope.SuccessResponse(nil, "Message deleted successfully")
+}
+
+// ImportMessages reads a JSON or CSV body (?format= or the Content-Type) into ?lang=.
+func (h *MessageHandler) ImportMessages(c *gin.Context) envelope.Response {
+	count, err := h.service.Import(c.Request.Context(), c.Query("lang"), requestFormat(c), c.Request.Body)
+	if err != nil {
+		// These describe the request or the file, so their text is safe to return.
+		switch {
+		case errors.Is(err, message_services.ErrUnsupportedFormat), errors.Is(err, message_services.ErrInvalidMessage):
+			return envelope.ErrorResponse(http.StatusBadRequest, err.Error(), core_errors.ErrMessageInvalidRequest)
+		case errors.Is(err, message_services.ErrInvalidImport):
+			return envelope.ErrorResponse(http.StatusUnprocessableEntity, err.Error(), core_errors.ErrMessageImportError)
+		}
+		return envelope.FromError(err)
+	}
+	return envelope.SuccessResponse(gin.H{"imported": count}, "Messages imported successfully")
+}
+
+// ExportMessages downloads the messages of ?lang= as a JSON or CSV file.
+func (h *MessageHandler) ExportMessages(c *gin.Context) {
+	lang, format := c.Query("lang"), c.DefaultQuery("format", message_services.FormatJSON)
+	if lang == "" || (format != message_services.FormatJSON && format != message_services.FormatCSV) {
+		envelope.Abort(c, envelope.ErrorResponse(http.StatusBadRequest, "lang and a json or csv format are required", core_errors.ErrMessageInvalidRequest))
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == message_services.FormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="messages_%s.%s"`, lang, format))
+	c.Status(http.StatusOK)
+
+	if err := h.service.Export(c.Request.Context(), lang, format, c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+func requestFormat(c *gin.Context) string {
+	if format := c.Query("format"); format != "" {
+		return format
+	}
+	if strings.HasPrefix(c.ContentType(), "text/csv") {
+		return message_services.FormatCSV
+	}
+	return message_services.FormatJSON
+}
+
+// messageErrorResponse maps the repository and service errors to AppErrors.
+func messageErrorResponse(err error) envelope.Response {
+	switch {
+	case errors.Is(err, message_repositories.ErrMessageNotFound):
+		err = core_errors.ErrMessageNotFound.Wrap(err)
+	case errors.Is(err, message_repositories.ErrMessageExists):
+		err = core_errors.ErrMessageAlreadyExists.Wrap(err)
+	case errors.Is(err, message_services.ErrInvalidMessage):
+		err = core_errors.ErrMessageInvalidRequest.Wrap(err)
+	}
+	return envelope.FromError(err)
+}
@@ -0,0 +1,53 @@
+package i18n_handlers
+
+import (
+	"net/http"
+	"pengi-med-saas/core/envelope"
+	core_errors "pengi-med-saas/core/errors"
+	"pengi-med-saas/core/query"
+	"pengi-med-saas/core/validation"
+	message_repositories "pengi-med-saas/i18n/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type overrideRequest struct {
+	Value string `json:"value" binding:"required"`
+}
+
+// ListOverrides pages through the current tenant's messages, e.g. ?lang=es.
+func (h *MessageHandler) ListOverrides(c *gin.Context) envelope.Response {
+	params, err := query.Parse(c.Request.URL.Query(), message_repositories.MessageQuery)
+	if err != nil {
+		return envelope.InvalidQuery(err)
+	}
+
+	overrides, err := h.service.ListOverrides(c.Request.Context(), c.GetUint("tenant_id"), params)
+	if err != nil {
+		return envelope.FromError(err)
+	}
+	return envelope.Paginated(overrides, "Messages obtained successfully")
+}
+
+// SetOverride creates or replaces the current tenant's wording for :lang/:key.
+func (h *MessageHandler) SetOverride(c *gin.Context) envelope.Response {
+	var req overrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return envelope.ErrorResponse(http.StatusBadRequest, "Invalid message request",
+			core_errors.ErrMessageInvalidRequest.WithDetails(validation.Details(err)))
+	}
+
+	override, err := h.service.SetOverride(c.Request.Context(), c.GetUint("tenant_id"), c.Param("lang"), c.Param("key"), req.Value)
+	if err != nil {
+		return messageErrorResponse(err)
+	}
+	return envelope.SuccessResponse(override, "Message updated successfully")
+}
+
+// DeleteOverride makes the current tenant use the global message for :lang/:key again.
+func (h *MessageHandler) DeleteOverride(c *gin.Context) envelope.Response {
+	if err := h.service.DeleteOverride(c.Request.Context(), c.GetUint("tenant_id"), c.Param("lang"), c.Param("key")); err != nil {
+		return messageErrorResponse(err)
+	}
+	return envelope.SuccessResponse(nil, "Message deleted successfully")
+}
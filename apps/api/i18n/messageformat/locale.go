@@ -0,0 +1,158 @@
+package message_format
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+locale holds the CLDR data the formatter needs for one language. Only the
+languages the API ships messages for are included; others use English.
+*/
+type locale struct {
+	decimal string
+	group   string
+	// minGrouping is how many digits the integer part needs beyond the first
+	// group before separators are used (Spanish writes 1000 but 10.000).
+	minGrouping int
+
+	cardinal func(n float64) string
+	ordinal  func(n float64) string
+
+	months      []string
+	shortMonths []string
+	weekdays    []string
+	dateFormats map[string]func(t time.Time, l *locale) string
+	timeFormats map[string]string
+}
+
+var locales = map[string]*locale{
+	"en": {
+		decimal:     ".",
+		group:       ",",
+		minGrouping: 1,
+		cardinal: func(n float64) string {
+			if n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+		ordinal: func(n float64) string {
+			i := int64(n)
+			switch {
+			case i%10 == 1 && i%100 != 11:
+				return "one"
+			case i%10 == 2 && i%100 != 12:
+				return "two"
+			case i%10 == 3 && i%100 != 13:
+				return "few"
+			}
+			return "other"
+		},
+		months:      []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		shortMonths: []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		weekdays:    []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		dateFormats: map[string]func(time.Time, *locale) string{
+			"short":  func(t time.Time, _ *locale) string { return t.Format("1/2/06") },
+			"medium": func(t time.Time, l *locale) string { return l.shortMonths[t.Month()-1] + t.Format(" 2, 2006") },
+			"long":   func(t time.Time, l *locale) string { return l.months[t.Month()-1] + t.Format(" 2, 2006") },
+			"full": func(t time.Time, l *locale) string {
+				return l.weekdays[t.Weekday()] + ", " + l.months[t.Month()-1] + t.Format(" 2, 2006")
+			},
+		},
+		timeFormats: map[string]string{"short": "3:04 PM", "medium": "3:04:05 PM", "long": "3:04:05 PM MST", "full": "3:04:05 PM MST"},
+	},
+	"es": {
+		decimal:     ",",
+		group:       ".",
+		minGrouping: 2,
+		cardinal: func(n float64) string {
+			if n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+		ordinal:     func(float64) string { return "other" },
+		months:      []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		shortMonths: []string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		weekdays:    []string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		dateFormats: map[string]func(time.Time, *locale) string{
+			"short": func(t time.Time, _ *locale) string { return t.Format("2/1/06") },
+			"medium": func(t time.Time, l *locale) string {
+				return t.Format("2 ") + l.shortMonths[t.Month()-1] + t.Format(" 2006")
+			},
+			"long": func(t time.Time, l *locale) string {
+				return t.Format("2 de ") + l.months[t.Month()-1] + t.Format(" de 2006")
+			},
+			"full": func(t time.Time, l *locale) string {
+				return l.weekdays[t.Weekday()] + t.Format(", 2 de ") + l.months[t.Month()-1] + t.Format(" de 2006")
+			},
+		},
+		timeFormats: map[string]string{"short": "15:04", "medium": "15:04:05", "long": "15:04:05 MST", "full": "15:04:05 MST"},
+	},
+}
+
+// localeFor returns the locale of a language tag such as es-EC.
+func localeFor(lang string) *locale {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	if l, ok := locales[base]; ok {
+		return l
+	}
+	return locales["en"]
+}
+
+// formatNumber writes n with the locale's separators and at most three
+// decimals, like ICU's default number format.
+func (l *locale) formatNumber(n float64) string {
+	if math.IsInf(n, 0) || math.IsNaN(n) {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	digits := strconv.FormatFloat(n, 'f', 3, 64)
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	fracPart = strings.TrimRight(fracPart, "0")
+
+	if len(intPart) > 3+l.minGrouping-1 {
+		var grouped strings.Builder
+		for i, r := range intPart {
+			if i > 0 && (len(intPart)-i)%3 == 0 {
+				grouped.WriteString(l.group)
+			}
+			grouped.WriteRune(r)
+		}
+		intPart = grouped.String()
+	}
+
+	if fracPart == "" {
+		return sign + intPart
+	}
+	return sign + intPart + l.decimal + fracPart
+}
+
+func (l *locale) formatDate(t time.Time, style string) (string, bool) {
+	if style == "" {
+		style = "medium"
+	}
+	format, ok := l.dateFormats[style]
+	if !ok {
+		return "", false
+	}
+	return format(t, l), true
+}
+
+func (l *locale) formatTime(t time.Time, style string) (string, bool) {
+	if style == "" {
+		style = "medium"
+	}
+	layout, ok := l.timeFormats[style]
+	if !ok {
+		return "", false
+	}
+	return t.Format(layout), true
+}
@@ -0,0 +1,216 @@
+/*
+Package message_format implementa el subconjunto de ICU MessageFormat que usan
+los mensajes de la API: interpolación simple, number/date/time, plural,
+selectordinal y select.
+
+	{count, plural, =0 {Sin citas} one {# cita} other {# citas}}
+	{gender, select, female {Bienvenida} other {Bienvenido}}, {name}
+	Vence el {expires, date, long}
+*/
+package message_format
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parsed caches patterns by source text; messages are few and reused.
+var parsed sync.Map // map[string][]node
+
+// Format renders pattern for lang with the given arguments. Patterns without
+// arguments are returned unchanged.
+func Format(lang, pattern string, args map[string]any) (string, error) {
+	if !strings.ContainsAny(pattern, "{'") {
+		return pattern, nil
+	}
+
+	nodes, err := compile(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	f := formatter{locale: localeFor(lang), args: args}
+	var out strings.Builder
+	if err := f.write(&out, nodes, nil); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func compile(pattern string) ([]node, error) {
+	if nodes, ok := parsed.Load(pattern); ok {
+		return nodes.([]node), nil
+	}
+	nodes, err := parse(pattern)
+	if err != nil {
+		return nil, err
+	}
+	parsed.Store(pattern, nodes)
+	return nodes, nil
+}
+
+type formatter struct {
+	locale *locale
+	args   map[string]any
+}
+
+// write renders nodes; pound is the value # stands for inside a plural case.
+func (f formatter) write(out *strings.Builder, nodes []node, pound *float64) error {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case textNode:
+			out.WriteString(string(n))
+		case poundNode:
+			if pound == nil {
+				out.WriteByte('#')
+				continue
+			}
+			out.WriteString(f.locale.formatNumber(*pound))
+		case argNode:
+			s, err := f.argument(n)
+			if err != nil {
+				return err
+			}
+			out.WriteString(s)
+		case pluralNode:
+			value, err := f.number(n.name)
+			if err != nil {
+				return err
+			}
+			value -= n.offset
+			if err := f.write(out, f.pluralCase(n, value+n.offset, value), &value); err != nil {
+				return err
+			}
+		case selectNode:
+			value, ok := f.args[n.name]
+			if !ok {
+				return fmt.Errorf("message format: missing argument %q", n.name)
+			}
+			message, ok := n.cases[fmt.Sprint(value)]
+			if !ok {
+				message = n.cases["other"]
+			}
+			if err := f.write(out, message, pound); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pluralCase picks an exact "=N" match on the raw value first and otherwise
+// the locale's category for the value minus the offset.
+func (f formatter) pluralCase(n pluralNode, raw, value float64) []node {
+	if message, ok := n.cases["="+trimFloat(raw)]; ok {
+		return message
+	}
+	rule := f.locale.cardinal
+	if n.ordinal {
+		rule = f.locale.ordinal
+	}
+	if message, ok := n.cases[rule(value)]; ok {
+		return message
+	}
+	return n.cases["other"]
+}
+
+func (f formatter) argument(n argNode) (string, error) {
+	value, ok := f.args[n.name]
+	if !ok {
+		return "", fmt.Errorf("message format: missing argument %q", n.name)
+	}
+
+	switch n.typ {
+	case "number":
+		number, ok := toFloat(value)
+		if !ok {
+			return "", fmt.Errorf("message format: argument %q is not a number", n.name)
+		}
+		switch n.style {
+		case "":
+			return f.locale.formatNumber(number), nil
+		case "integer":
+			return f.locale.formatNumber(math.Round(number)), nil
+		case "percent":
+			return f.locale.formatNumber(math.Round(number*100)) + "%", nil
+		}
+		return "", fmt.Errorf("message format: unknown number style %q", n.style)
+	case "date", "time":
+		t, ok := value.(time.Time)
+		if !ok {
+			return "", fmt.Errorf("message format: argument %q is not a time", n.name)
+		}
+		format := f.locale.formatDate
+		if n.typ == "time" {
+			format = f.locale.formatTime
+		}
+		s, ok := format(t, n.style)
+		if !ok {
+			return "", fmt.Errorf("message format: unknown %s style %q", n.typ, n.style)
+		}
+		return s, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case time.Time:
+		s, _ := f.locale.formatDate(v, "")
+		return s, nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	if number, ok := toFloat(value); ok {
+		return f.locale.formatNumber(number), nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+func (f formatter) number(name string) (float64, error) {
+	value, ok := f.args[name]
+	if !ok {
+		return 0, fmt.Errorf("message format: missing argument %q", name)
+	}
+	number, ok := toFloat(value)
+	if !ok {
+		return 0, fmt.Errorf("message format: argument %q is not a number", name)
+	}
+	return number, nil
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func trimFloat(n float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%f", n), "0"), ".")
+}
@@ -0,0 +1,106 @@
+package message_format
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2026, time.March, 5, 14, 7, 9, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		lang    string
+		pattern string
+		args    map[string]any
+		want    string
+	}{
+		{"plain text", "en", "Users obtained successfully", nil, "Users obtained successfully"},
+		{"simple argument", "en", "Hello, {name}!", map[string]any{"name": "Ana"}, "Hello, Ana!"},
+
+		{"plural exact", "en", "{count, plural, =0 {No appointments} one {# appointment} other {# appointments}}", map[string]any{"count": 0}, "No appointments"},
+		{"plural one", "en", "{count, plural, =0 {No appointments} one {# appointment} other {# appointments}}", map[string]any{"count": 1}, "1 appointment"},
+		{"plural other", "en", "{count, plural, =0 {No appointments} one {# appointment} other {# appointments}}", map[string]any{"count": 1234}, "1,234 appointments"},
+		{"plural es", "es", "{count, plural, one {# cita} other {# citas}}", map[string]any{"count": 12345}, "12.345 citas"},
+		{"plural es min grouping", "es", "{count, plural, one {# cita} other {# citas}}", map[string]any{"count": 1234}, "1234 citas"},
+		{"plural exact beats category", "en", "{count, plural, =1 {Just one} one {# item} other {# items}}", map[string]any{"count": 1}, "Just one"},
+
+		{"offset exact", "en", "{guests, plural, offset:1 =0 {Nobody} =1 {{host} alone} one {{host} and one other} other {{host} and # others}}", map[string]any{"guests": 1, "host": "Ana"}, "Ana alone"},
+		{"offset one", "en", "{guests, plural, offset:1 =0 {Nobody} =1 {{host} alone} one {{host} and one other} other {{host} and # others}}", map[string]any{"guests": 2, "host": "Ana"}, "Ana and one other"},
+		{"offset other", "en", "{guests, plural, offset:1 =0 {Nobody} =1 {{host} alone} one {{host} and one other} other {{host} and # others}}", map[string]any{"guests": 5, "host": "Ana"}, "Ana and 4 others"},
+
+		{"selectordinal one", "en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", map[string]any{"n": 1}, "1st"},
+		{"selectordinal two", "en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", map[string]any{"n": 22}, "22nd"},
+		{"selectordinal few", "en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", map[string]any{"n": 3}, "3rd"},
+		{"selectordinal teens", "en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", map[string]any{"n": 11}, "11th"},
+		{"selectordinal es", "es", "{n, selectordinal, other {#.º}}", map[string]any{"n": 2}, "2.º"},
+
+		{"select", "es", "{gender, select, female {Bienvenida} other {Bienvenido}}, {name}", map[string]any{"gender": "female", "name": "Ana"}, "Bienvenida, Ana"},
+		{"select other", "es", "{gender, select, female {Bienvenida} other {Bienvenido}}", map[string]any{"gender": "x"}, "Bienvenido"},
+		{"select pound is literal", "en", "{gender, select, other {Room #1}}", map[string]any{"gender": "x"}, "Room #1"},
+		{"select inside plural", "es", "{count, plural, one {# paciente} other {{gender, select, female {# pacientes nuevas} other {# pacientes nuevos}}}}", map[string]any{"count": 3, "gender": "female"}, "3 pacientes nuevas"},
+
+		{"quoted braces", "en", "'{name}' is literal, {name} is not", map[string]any{"name": "Ana"}, "{name} is literal, Ana is not"},
+		{"doubled apostrophe", "en", "It''s {name}", map[string]any{"name": "Ana"}, "It's Ana"},
+		{"lone apostrophe", "en", "don't panic, {name}", map[string]any{"name": "Ana"}, "don't panic, Ana"},
+		{"quoted pound", "en", "{n, plural, other {# items, not '#'}}", map[string]any{"n": 2}, "2 items, not #"},
+
+		{"number en", "en", "{n, number}", map[string]any{"n": 1234.5}, "1,234.5"},
+		{"number es", "es", "{n, number}", map[string]any{"n": 12345.5}, "12.345,5"},
+		{"number decimals", "en", "{n, number}", map[string]any{"n": 0.12345}, "0.123"},
+		{"number negative", "es", "{n, number}", map[string]any{"n": -1234567}, "-1.234.567"},
+		{"number integer", "en", "{n, number, integer}", map[string]any{"n": 2.6}, "3"},
+		{"number percent", "es", "{n, number, percent}", map[string]any{"n": 0.25}, "25%"},
+		{"region tag", "es-EC", "{n, number}", map[string]any{"n": 12345.5}, "12.345,5"},
+		{"unknown language", "fr", "{n, number}", map[string]any{"n": 1234.5}, "1,234.5"},
+
+		{"date default en", "en", "{d, date}", map[string]any{"d": date}, "Mar 5, 2026"},
+		{"date default es", "es", "{d, date}", map[string]any{"d": date}, "5 mar 2026"},
+		{"date short en", "en", "{d, date, short}", map[string]any{"d": date}, "3/5/26"},
+		{"date short es", "es", "{d, date, short}", map[string]any{"d": date}, "5/3/26"},
+		{"date long en", "en", "{d, date, long}", map[string]any{"d": date}, "March 5, 2026"},
+		{"date long es", "es", "Vence el {d, date, long}", map[string]any{"d": date}, "Vence el 5 de marzo de 2026"},
+		{"date full en", "en", "{d, date, full}", map[string]any{"d": date}, "Thursday, March 5, 2026"},
+		{"date full es", "es", "{d, date, full}", map[string]any{"d": date}, "jueves, 5 de marzo de 2026"},
+		{"time short en", "en", "{d, time, short}", map[string]any{"d": date}, "2:07 PM"},
+		{"time short es", "es", "{d, time, short}", map[string]any{"d": date}, "14:07"},
+		{"time argument", "es", "{d}", map[string]any{"d": date}, "5 mar 2026"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format(tt.lang, tt.pattern, tt.args)
+			if err != nil {
+				t.Fatalf("Format(%q) error: %v", tt.pattern, err)
+			}
+			if got != tt.want {
+				t.Errorf("Format(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		args    map[string]any
+	}{
+		{"missing argument", "Hello, {name}", nil},
+		{"not a number", "{n, plural, other {#}}", map[string]any{"n": "many"}},
+		{"not a time", "{d, date}", map[string]any{"d": "today"}},
+		{"missing other", "{n, plural, one {#}}", map[string]any{"n": 1}},
+		{"unterminated", "{n, plural, other {#}", map[string]any{"n": 1}},
+		{"unknown type", "{n, currency}", map[string]any{"n": 1}},
+		{"unknown style", "{n, number, money}", map[string]any{"n": 1}},
+		{"stray brace", "{name} done}", map[string]any{"name": "Ana"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Format("en", tt.pattern, tt.args); err == nil {
+				t.Errorf("Format(%q) = %q, want an error", tt.pattern, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package message_format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type node interface{}
+
+type (
+	// textNode is literal text.
+	textNode string
+	// poundNode is # inside a plural case: the plural value minus the offset.
+	poundNode struct{}
+	// argNode is {name}, {name, type} or {name, type, style}.
+	argNode struct {
+		name  string
+		typ   string
+		style string
+	}
+	// pluralNode is {name, plural, ...} or {name, selectordinal, ...}.
+	pluralNode struct {
+		name    string
+		ordinal bool
+		offset  float64
+		cases   map[string][]node // "=2", "one", "other"...
+	}
+	// selectNode is {name, select, ...}.
+	selectNode struct {
+		name  string
+		cases map[string][]node
+	}
+)
+
+type parser struct {
+	src []rune
+	pos int
+}
+
+func parse(pattern string) ([]node, error) {
+	p := &parser{src: []rune(pattern)}
+	nodes, err := p.message(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected '}'")
+	}
+	return nodes, nil
+}
+
+// message parses text and arguments until an unmatched '}' or the end.
+func (p *parser) message(inPlural bool) ([]node, error) {
+	var nodes []node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		switch {
+		case r == '\'':
+			p.quoted(&text, inPlural)
+		case r == '{':
+			flush()
+			arg, err := p.argument(inPlural)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, arg)
+		case r == '}':
+			flush()
+			return nodes, nil
+		case r == '#' && inPlural:
+			flush()
+			nodes = append(nodes, poundNode{})
+			p.pos++
+		default:
+			text.WriteRune(r)
+			p.pos++
+		}
+	}
+	flush()
+	return nodes, nil
+}
+
+/*
+quoted handles an apostrophe the way ICU does: a doubled apostrophe is a
+literal one and an apostrophe before a syntax character starts quoted text up
+to the next single apostrophe. Any other apostrophe is literal, so "don't" needs no escaping.
+*/
+func (p *parser) quoted(text *strings.Builder, inPlural bool) {
+	p.pos++ // opening '
+	if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+		text.WriteRune('\'')
+		p.pos++
+		return
+	}
+	if p.pos >= len(p.src) || !isSyntax(p.src[p.pos], inPlural) {
+		text.WriteRune('\'')
+		return
+	}
+
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		p.pos++
+		if r != '\'' {
+			text.WriteRune(r)
+			continue
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+			text.WriteRune('\'')
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+func isSyntax(r rune, inPlural bool) bool {
+	return r == '{' || r == '}' || (inPlural && r == '#')
+}
+
+// argument parses {...}; inPlural is whether it sits inside a plural case, so
+// # in a nested select still stands for the plural value.
+func (p *parser) argument(inPlural bool) (node, error) {
+	p.pos++ // {
+	p.skipSpace()
+	name := p.identifier()
+	if name == "" {
+		return nil, p.errorf("missing argument name")
+	}
+	p.skipSpace()
+
+	if p.consume('}') {
+		return argNode{name: name}, nil
+	}
+	if !p.consume(',') {
+		return nil, p.errorf("expected ',' or '}' after argument %q", name)
+	}
+	p.skipSpace()
+	typ := p.identifier()
+	p.skipSpace()
+
+	switch typ {
+	case "plural", "selectordinal":
+		if !p.consume(',') {
+			return nil, p.errorf("expected ',' after %s", typ)
+		}
+		return p.plural(name, typ == "selectordinal")
+	case "select":
+		if !p.consume(',') {
+			return nil, p.errorf("expected ',' after select")
+		}
+		cases, err := p.cases(inPlural)
+		if err != nil {
+			return nil, err
+		}
+		return selectNode{name: name, cases: cases}, nil
+	case "number", "date", "time":
+		style := ""
+		if p.consume(',') {
+			start := p.pos
+			for p.pos < len(p.src) && p.src[p.pos] != '}' {
+				p.pos++
+			}
+			style = strings.TrimSpace(string(p.src[start:p.pos]))
+		}
+		if !p.consume('}') {
+			return nil, p.errorf("unterminated argument %q", name)
+		}
+		return argNode{name: name, typ: typ, style: style}, nil
+	case "":
+		return nil, p.errorf("missing type for argument %q", name)
+	default:
+		return nil, p.errorf("unknown argument type %q", typ)
+	}
+}
+
+func (p *parser) plural(name string, ordinal bool) (node, error) {
+	n := pluralNode{name: name, ordinal: ordinal}
+
+	p.skipSpace()
+	if strings.HasPrefix(string(p.src[p.pos:]), "offset:") {
+		p.pos += len("offset:")
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.src) && (unicode.IsDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		offset, err := strconv.ParseFloat(string(p.src[start:p.pos]), 64)
+		if err != nil {
+			return nil, p.errorf("invalid plural offset")
+		}
+		n.offset = offset
+	}
+
+	cases, err := p.cases(true)
+	if err != nil {
+		return nil, err
+	}
+	n.cases = cases
+	return n, nil
+}
+
+// cases parses "selector {message}" pairs up to the closing '}' of the argument.
+func (p *parser) cases(inPlural bool) (map[string][]node, error) {
+	cases := make(map[string][]node)
+	for {
+		p.skipSpace()
+		if p.consume('}') {
+			break
+		}
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated argument")
+		}
+
+		start := p.pos
+		if p.src[p.pos] == '=' {
+			p.pos++
+		}
+		selector := string(p.src[start:p.pos]) + p.identifier()
+		if selector == "" || selector == "=" {
+			return nil, p.errorf("missing case selector")
+		}
+
+		p.skipSpace()
+		if !p.consume('{') {
+			return nil, p.errorf("expected '{' after case %q", selector)
+		}
+		message, err := p.message(inPlural)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume('}') {
+			return nil, p.errorf("unterminated case %q", selector)
+		}
+		cases[selector] = message
+	}
+
+	if _, ok := cases["other"]; !ok {
+		return nil, p.errorf("missing 'other' case")
+	}
+	return cases, nil
+}
+
+func (p *parser) identifier() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(r rune) bool {
+	if p.pos < len(p.src) && p.src[p.pos] == r {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("message format: %s at offset %d", fmt.Sprintf(format, args...), p.pos)
+}
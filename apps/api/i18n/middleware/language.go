@@ -0,0 +1,91 @@
+package i18n_middleware
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type languageRange struct {
+	tag string
+	q   float64
+}
+
+// parseAcceptLanguage returns the ranges of an Accept-Language header ordered
+// by preference. Ranges with q=0, which the client refuses, are dropped.
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if q > 0 {
+			ranges = append(ranges, languageRange{tag: tag, q: q})
+		}
+	}
+
+	// Stable, so equal q-values keep the client's order.
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	return ranges
+}
+
+/*
+negotiateLanguage picks the available language that best matches an
+Accept-Language header, using RFC 4647 lookup: each range, in order of
+preference, is tried as is and then with its last subtag removed, so es-EC
+falls back to es. A range also matches a regional variant when only the
+variant is available (es matches es-EC). The wildcard "*" never matches, which
+leaves the choice to the caller's default.
+*/
+func negotiateLanguage(header string, available []string) (string, bool) {
+	byTag := make(map[string]string, len(available))
+	for _, lang := range available {
+		byTag[strings.ToLower(lang)] = lang
+	}
+
+	for _, r := range parseAcceptLanguage(header) {
+		if r.tag == "*" {
+			continue
+		}
+		for tag := r.tag; tag != ""; tag = truncateTag(tag) {
+			if lang, ok := byTag[tag]; ok {
+				return lang, true
+			}
+		}
+		for _, lang := range available {
+			if strings.HasPrefix(strings.ToLower(lang), r.tag+"-") {
+				return lang, true
+			}
+		}
+	}
+	return "", false
+}
+
+// truncateTag removes the last subtag, along with a preceding single-letter
+// subtag such as the x in es-x-private, as RFC 4647 section 3.4 requires.
+func truncateTag(tag string) string {
+	i := strings.LastIndex(tag, "-")
+	if i < 0 {
+		return ""
+	}
+	tag = tag[:i]
+	if j := strings.LastIndex(tag, "-"); j >= 0 && len(tag)-j == 2 {
+		tag = tag[:j]
+	}
+	return tag
+}
@@ -0,0 +1,133 @@
+package i18n_middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	tenant_models "pengi-med-saas/features/tenants/models"
+	tenant_repositories "pengi-med-saas/features/tenants/repositories"
+	tenant_services "pengi-med-saas/features/tenants/services"
+	message_cache "pengi-med-saas/i18n/cache"
+	message_models "pengi-med-saas/i18n/models"
+	message_repositories "pengi-med-saas/i18n/repositories"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	available := []string{"es", "en", "pt-BR"}
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"exact", "en", "en"},
+		{"case insensitive", "EN", "en"},
+		{"regional falls back", "es-EC", "es"},
+		{"private use falls back", "es-x-private", "es"},
+		{"matches the only variant", "pt", "pt-BR"},
+		{"q-values reorder", "es;q=0.5, en;q=0.8", "en"},
+		{"equal q keeps client order", "en;q=0.5, es;q=0.5", "en"},
+		{"missing q is 1", "es, en;q=0.9", "es"},
+		{"q=0 refuses", "en;q=0, es;q=0.1", "es"},
+		{"invalid q refuses", "en;q=abc, es;q=0.2", "es"},
+		{"q above 1 refuses", "en;q=2, es;q=0.2", "es"},
+		{"skips unknown", "de-DE, fr;q=0.9, en;q=0.1", "en"},
+		{"nothing known", "de, fr", ""},
+		{"wildcard alone", "*", ""},
+		{"wildcard first", "*, en;q=0.5", "en"},
+		{"only refusals", "es;q=0, en;q=0", ""},
+		{"blank ranges", " , ,en", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := negotiateLanguage(tt.header, available)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("negotiateLanguage(%q) = %q, %v; want %q", tt.header, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateTag(t *testing.T) {
+	tests := map[string]string{
+		"es-ec":           "es",
+		"es":              "",
+		"zh-hant-tw":      "zh-hant",
+		"es-x-private":    "es",
+		"en-us-x-twain-a": "en-us-x-twain",
+	}
+	for tag, want := range tests {
+		if got := truncateTag(tag); got != want {
+			t.Errorf("truncateTag(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+// TestI18nMiddlewareDefaults checks the order of the fallbacks when nothing
+// requested is available: the tenant's default language, then defaultLang.
+func TestI18nMiddlewareDefaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cache := message_cache.NewSync(nil, message_repositories.NewMemoryMessageRepository(
+		message_models.Message{Key: "hello", Value: "Hola", Lang: "es"},
+		message_models.Message{Key: "hello", Value: "Hello", Lang: "en"},
+	))
+	// The cache is global: load these messages over any loaded before.
+	if err := cache.Invalidate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	tenants := tenant_services.NewTenantService(tenant_repositories.NewMemoryTenantRepository(
+		tenant_models.Tenant{Name: "Acme Health", Slug: "acme"},
+		tenant_models.Tenant{Name: "Globex Clinics", Slug: "globex", DefaultLang: "en"},
+	))
+
+	router := gin.New()
+	router.Use(I18nMiddleware(cache, tenants, "es"))
+	router.GET("/", func(c *gin.Context) { c.String(http.StatusOK, c.GetString("lang")) })
+
+	tests := []struct {
+		name   string
+		tenant string
+		header string
+		query  string
+		want   string
+	}{
+		{"header wins over tenant default", "globex", "es-EC", "", "es"},
+		{"query wins over header", "", "es", "en-GB", "en"},
+		{"tenant default", "globex", "de", "", "en"},
+		{"tenant without default", "acme", "de", "", "es"},
+		{"unknown tenant", "initech", "de", "", "es"},
+		{"no tenant", "", "", "", "es"},
+		{"wildcard uses tenant default", "globex", "*", "", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/"
+			if tt.query != "" {
+				target += "?lang=" + tt.query
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			if tt.tenant != "" {
+				req.Header.Set("X-Tenant-Slug", tt.tenant)
+			}
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("lang = %q, want %q", got, tt.want)
+			}
+			if got := rec.Header().Get("Content-Language"); got != tt.want {
+				t.Errorf("Content-Language = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
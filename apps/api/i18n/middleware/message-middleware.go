@@ -1,29 +1,61 @@
 package i18n_middleware
 
 import (
+	"pengi-med-saas/core/logger"
+	tenant_services "pengi-med-saas/features/tenants/services"
 	message_cache "pengi-med-saas/i18n/cache"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"go.uber.org/zap"
 )
 
-func I18nMiddleware(db *gorm.DB) gin.HandlerFunc {
+/*
+I18nMiddleware sets the request language and a translator that applies the
+tenant's message overrides. The tenant comes from the X-Tenant-Slug header;
+requests without one, or with an unknown slug, get the global messages.
+
+The language is the best match among the languages that have messages for
+the ?lang= query parameter or, without one, for Accept-Language; ?lang=es-EC
+falls back to es like the header does. When nothing matches it is the
+tenant's default language, then defaultLang. It is echoed in Content-Language.
+
+The translator formats messages as ICU MessageFormat with the arguments a
+Response or AppError carries, see message_format.Format.
+*/
+func I18nMiddleware(cache *message_cache.Sync, tenants *tenant_services.TenantService, defaultLang string) gin.HandlerFunc {
+	message_cache.SetFallbackLang(defaultLang)
+
 	// Initialize cache once
-	_ = message_cache.Init(db)
+	_ = cache.Init()
 
 	return func(c *gin.Context) {
-		lang := c.GetHeader("Accept-Language")
+		tenantDefault := ""
+		if slug := c.GetHeader("X-Tenant-Slug"); slug != "" && tenants != nil {
+			// TenantMiddleware reuses the lookup instead of repeating it.
+			if tenant, err := tenants.FindBySlug(c.Request.Context(), slug); err == nil {
+				c.Set("tenant_id", tenant.ID)
+				c.Request = c.Request.WithContext(logger.With(c.Request.Context(), zap.Uint("tenant_id", tenant.ID)))
+				tenantDefault = tenant.DefaultLang
+			}
+		}
+
+		requested := c.GetHeader("Accept-Language")
+		if query := c.Query("lang"); query != "" {
+			requested = query
+		}
+		lang, _ := negotiateLanguage(requested, message_cache.Languages())
 		if lang == "" {
-			lang = c.Query("lang")
+			lang = tenantDefault
 		}
 		if lang == "" {
-			lang = "es"
+			lang = defaultLang
 		}
 
 		c.Set("lang", lang)
-		c.Set("translator", func(key string) string {
-			return message_cache.Get(lang, key)
+		c.Set("translator", func(key string, args map[string]any) string {
+			return message_cache.FormatContext(c.Request.Context(), c.GetUint("tenant_id"), lang, key, args)
 		})
+		c.Header("Content-Language", lang)
 
 		c.Next()
 	}
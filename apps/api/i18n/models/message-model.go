@@ -4,15 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	tenant_models "pengi-med-saas/features/tenants/models"
 
 	"gorm.io/gorm"
 )
 
 type Message struct {
 	gorm.Model
-	Key   string `gorm:"uniqueIndex:idx_key_lang;not null" json:"key"`
+	Key   string `gorm:"uniqueIndex:idx_messages_global_key,where:tenant_id IS NULL;uniqueIndex:idx_messages_tenant_key,where:tenant_id IS NOT NULL;not null" json:"key"`
 	Value string `gorm:"type:text;not null" json:"value"`
-	Lang  string `gorm:"uniqueIndex:idx_key_lang;not null;default:es" json:"lang"` // ej: "es"
+	Lang  string `gorm:"uniqueIndex:idx_messages_global_key;uniqueIndex:idx_messages_tenant_key;not null;default:es" json:"lang"` // ej: "es"
+	// TenantID scopes the message to one tenant, overriding the global
+	// message with the same key and language. Nil for global messages.
+	TenantID *uint                 `gorm:"index;uniqueIndex:idx_messages_tenant_key,priority:1" json:"tenant_id,omitempty"`
+	Tenant   *tenant_models.Tenant `gorm:"constraint:OnDelete:CASCADE" json:"-"`
 }
 
 func NewMessage(key, value, lang string) *Message {
@@ -23,10 +28,6 @@ func NewMessage(key, value, lang string) *Message {
 	}
 }
 
-func (m *Message) Save(db *gorm.DB) error {
-	return db.Save(m).Error
-}
-
 func LoadMessagesFromFile(db *gorm.DB, filePath string, lang string) error {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -42,7 +43,7 @@ func LoadMessagesFromFile(db *gorm.DB, filePath string, lang string) error {
 		msg.Lang = lang
 		// Verificar si el mensaje ya existe
 		var existingMsg Message
-		result := db.Where("key = ? AND lang = ?", msg.Key, lang).Limit(1).Find(&existingMsg)
+		result := db.Where("key = ? AND lang = ? AND tenant_id IS NULL", msg.Key, lang).Limit(1).Find(&existingMsg)
 		if result.RowsAffected == 0 {
 			// El mensaje no existe, lo guardamos
 			if err := db.Create(&msg).Error; err != nil {
@@ -0,0 +1,184 @@
+package message_repositories
+
+import (
+	"context"
+	"pengi-med-saas/core/query"
+	message_models "pengi-med-saas/i18n/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryMessageRepository is an in-memory MessageRepository for tests.
+type MemoryMessageRepository struct {
+	mu       sync.RWMutex
+	messages map[uint]message_models.Message
+	nextID   uint
+}
+
+func NewMemoryMessageRepository(messages ...message_models.Message) *MemoryMessageRepository {
+	r := &MemoryMessageRepository{messages: make(map[uint]message_models.Message)}
+	for _, message := range messages {
+		r.insert(&message)
+	}
+	return r
+}
+
+func (r *MemoryMessageRepository) List(ctx context.Context) ([]message_models.Message, error) {
+	return r.filter(func(message_models.Message) bool { return true }), nil
+}
+
+func (r *MemoryMessageRepository) ListByLang(ctx context.Context, lang string) ([]message_models.Message, error) {
+	return r.filter(func(m message_models.Message) bool { return m.Lang == lang && m.TenantID == nil }), nil
+}
+
+func (r *MemoryMessageRepository) Search(ctx context.Context, tenantID *uint, params query.Params) (query.Page[message_models.Message], error) {
+	return query.Apply(r.filter(func(m message_models.Message) bool { return sameTenant(m.TenantID, tenantID) }), params)
+}
+
+func (r *MemoryMessageRepository) FindByID(ctx context.Context, id uint) (*message_models.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	message, ok := r.messages[id]
+	if !ok {
+		return nil, ErrMessageNotFound
+	}
+	return &message, nil
+}
+
+func (r *MemoryMessageRepository) Create(ctx context.Context, message *message_models.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.find(message.TenantID, message.Key, message.Lang); exists {
+		return ErrMessageExists
+	}
+	r.insert(message)
+	return nil
+}
+
+func (r *MemoryMessageRepository) Update(ctx context.Context, message *message_models.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.messages[message.ID]
+	if !ok {
+		return ErrMessageNotFound
+	}
+	if other, exists := r.find(message.TenantID, message.Key, message.Lang); exists && other.ID != message.ID {
+		return ErrMessageExists
+	}
+	message.CreatedAt = existing.CreatedAt
+	message.UpdatedAt = time.Now()
+	r.messages[message.ID] = *message
+	return nil
+}
+
+func (r *MemoryMessageRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.messages[id]; !ok {
+		return ErrMessageNotFound
+	}
+	delete(r.messages, id)
+	return nil
+}
+
+func (r *MemoryMessageRepository) Upsert(ctx context.Context, messages []message_models.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, message := range messages {
+		message.TenantID = nil
+		if existing, exists := r.find(nil, message.Key, message.Lang); exists {
+			existing.Value = message.Value
+			existing.UpdatedAt = time.Now()
+			r.messages[existing.ID] = existing
+			continue
+		}
+		message.ID = 0
+		r.insert(&message)
+	}
+	return nil
+}
+
+func (r *MemoryMessageRepository) ListOverrides(ctx context.Context, tenantID uint, lang string) ([]message_models.Message, error) {
+	return r.filter(func(m message_models.Message) bool {
+		return m.TenantID != nil && *m.TenantID == tenantID && (lang == "" || m.Lang == lang)
+	}), nil
+}
+
+func (r *MemoryMessageRepository) UpsertOverride(ctx context.Context, message *message_models.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, exists := r.find(message.TenantID, message.Key, message.Lang); exists {
+		existing.Value = message.Value
+		existing.UpdatedAt = time.Now()
+		r.messages[existing.ID] = existing
+		*message = existing
+		return nil
+	}
+	r.insert(message)
+	return nil
+}
+
+func (r *MemoryMessageRepository) DeleteOverride(ctx context.Context, tenantID uint, lang, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.find(&tenantID, key, lang)
+	if !exists {
+		return ErrMessageNotFound
+	}
+	delete(r.messages, existing.ID)
+	return nil
+}
+
+func (r *MemoryMessageRepository) filter(keep func(message_models.Message) bool) []message_models.Message {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	messages := []message_models.Message{}
+	for _, message := range r.messages {
+		if keep(message) {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Lang != messages[j].Lang {
+			return messages[i].Lang < messages[j].Lang
+		}
+		return messages[i].Key < messages[j].Key
+	})
+	return messages
+}
+
+func (r *MemoryMessageRepository) find(tenantID *uint, key, lang string) (message_models.Message, bool) {
+	for _, message := range r.messages {
+		if message.Key == key && message.Lang == lang && sameTenant(message.TenantID, tenantID) {
+			return message, true
+		}
+	}
+	return message_models.Message{}, false
+}
+
+func sameTenant(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (r *MemoryMessageRepository) insert(message *message_models.Message) {
+	if message.ID == 0 {
+		r.nextID++
+		message.ID = r.nextID
+	} else if message.ID > r.nextID {
+		r.nextID = message.ID
+	}
+	message.CreatedAt, message.UpdatedAt = time.Now(), time.Now()
+	r.messages[message.ID] = *message
+}
@@ -0,0 +1,183 @@
+package message_repositories
+
+import (
+	"context"
+	"errors"
+	"pengi-med-saas/core/database"
+	"pengi-med-saas/core/query"
+	message_models "pengi-med-saas/i18n/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrMessageNotFound = errors.New("message not found")
+	ErrMessageExists   = errors.New("a message with this key and language already exists")
+)
+
+// MessageQuery is what the message list endpoints let clients filter and sort on.
+var MessageQuery = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.TypeInt, Operators: []query.Operator{query.Eq, query.In}, Sortable: true},
+		"key":        {Column: "key", Operators: []query.Operator{query.Eq, query.In, query.Like}, Sortable: true},
+		"lang":       {Column: "lang", Operators: []query.Operator{query.Eq, query.In}, Sortable: true},
+		"value":      {Column: "value", Operators: []query.Operator{query.Like}},
+		"updated_at": {Column: "updated_at", Type: query.TypeTime, Operators: []query.Operator{query.Gte}, Sortable: true},
+	},
+	DefaultSort: []query.Sort{{Column: "lang"}, {Column: "key"}},
+}
+
+type MessageRepository interface {
+	List(ctx context.Context) ([]message_models.Message, error)
+	// ListByLang lists the global messages of lang.
+	ListByLang(ctx context.Context, lang string) ([]message_models.Message, error)
+	// Search pages through the global messages, or a tenant's overrides
+	// when tenantID is set.
+	Search(ctx context.Context, tenantID *uint, params query.Params) (query.Page[message_models.Message], error)
+	FindByID(ctx context.Context, id uint) (*message_models.Message, error)
+	Create(ctx context.Context, message *message_models.Message) error
+	Update(ctx context.Context, message *message_models.Message) error
+	Delete(ctx context.Context, id uint) error
+	// Upsert creates the global messages or replaces the value of existing
+	// ones, matching on key and language.
+	Upsert(ctx context.Context, messages []message_models.Message) error
+
+	// ListOverrides lists a tenant's messages, in every language when lang is empty.
+	ListOverrides(ctx context.Context, tenantID uint, lang string) ([]message_models.Message, error)
+	// UpsertOverride creates or replaces the tenant's message with the same
+	// key and language; message.TenantID must be set.
+	UpsertOverride(ctx context.Context, message *message_models.Message) error
+	DeleteOverride(ctx context.Context, tenantID uint, lang, key string) error
+}
+
+type GormMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewGormMessageRepository(db *gorm.DB) *GormMessageRepository {
+	return &GormMessageRepository{db: db}
+}
+
+func (r *GormMessageRepository) List(ctx context.Context) ([]message_models.Message, error) {
+	messages := []message_models.Message{}
+	err := database.FromContext(ctx, r.db).Order("lang, key").Find(&messages).Error
+	return messages, err
+}
+
+func (r *GormMessageRepository) ListByLang(ctx context.Context, lang string) ([]message_models.Message, error) {
+	messages := []message_models.Message{}
+	err := database.FromContext(ctx, r.db).
+		Where("lang = ? AND tenant_id IS NULL", lang).
+		Order("key").
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *GormMessageRepository) Search(ctx context.Context, tenantID *uint, params query.Params) (query.Page[message_models.Message], error) {
+	db := database.FromContext(ctx, r.db)
+	if tenantID == nil {
+		db = db.Where("tenant_id IS NULL")
+	} else {
+		db = db.Where("tenant_id = ?", *tenantID)
+	}
+	return query.Find[message_models.Message](db, params)
+}
+
+func (r *GormMessageRepository) FindByID(ctx context.Context, id uint) (*message_models.Message, error) {
+	var message message_models.Message
+	if err := database.FromContext(ctx, r.db).First(&message, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *GormMessageRepository) Create(ctx context.Context, message *message_models.Message) error {
+	return translateError(database.FromContext(ctx, r.db).Create(message).Error)
+}
+
+func (r *GormMessageRepository) Update(ctx context.Context, message *message_models.Message) error {
+	result := database.FromContext(ctx, r.db).
+		Model(message).
+		Select("key", "value", "lang", "updated_at").
+		Updates(message)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMessageNotFound
+	}
+	return nil
+}
+
+// Delete removes the message for good, so its key can be created again.
+func (r *GormMessageRepository) Delete(ctx context.Context, id uint) error {
+	result := database.FromContext(ctx, r.db).Unscoped().Delete(&message_models.Message{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMessageNotFound
+	}
+	return nil
+}
+
+func (r *GormMessageRepository) Upsert(ctx context.Context, messages []message_models.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	return database.FromContext(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "key"}, {Name: "lang"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "tenant_id IS NULL"}}},
+			// Clearing deleted_at revives soft-deleted rows that still hold the key.
+			DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at", "deleted_at"}),
+		}).
+		CreateInBatches(messages, 500).Error
+}
+
+func (r *GormMessageRepository) ListOverrides(ctx context.Context, tenantID uint, lang string) ([]message_models.Message, error) {
+	messages := []message_models.Message{}
+	query := database.FromContext(ctx, r.db).Where("tenant_id = ?", tenantID)
+	if lang != "" {
+		query = query.Where("lang = ?", lang)
+	}
+	err := query.Order("lang, key").Find(&messages).Error
+	return messages, err
+}
+
+func (r *GormMessageRepository) UpsertOverride(ctx context.Context, message *message_models.Message) error {
+	return database.FromContext(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "tenant_id"}, {Name: "key"}, {Name: "lang"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "tenant_id IS NOT NULL"}}},
+			DoUpdates:   clause.AssignmentColumns([]string{"value", "updated_at", "deleted_at"}),
+		}).
+		Create(message).Error
+}
+
+func (r *GormMessageRepository) DeleteOverride(ctx context.Context, tenantID uint, lang, key string) error {
+	result := database.FromContext(ctx, r.db).
+		Unscoped().
+		Where("tenant_id = ? AND lang = ? AND key = ?", tenantID, lang, key).
+		Delete(&message_models.Message{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMessageNotFound
+	}
+	return nil
+}
+
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrMessageExists
+	}
+	return err
+}
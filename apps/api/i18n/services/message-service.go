@@ -0,0 +1,262 @@
+package message_services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pengi-med-saas/core/logger"
+	"pengi-med-saas/core/query"
+	message_models "pengi-med-saas/i18n/models"
+	message_repositories "pengi-med-saas/i18n/repositories"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported format, use json or csv")
+	ErrInvalidMessage    = errors.New("message key, value and lang are required")
+	// ErrInvalidImport wraps what is wrong with an import file; its text is
+	// meant for the client that sent the file.
+	ErrInvalidImport = errors.New("invalid import file")
+)
+
+// CacheInvalidator refreshes the translation cache after messages change.
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context) error
+}
+
+type MessageService struct {
+	messages message_repositories.MessageRepository
+	cache    CacheInvalidator
+}
+
+func NewMessageService(messages message_repositories.MessageRepository, cache CacheInvalidator) *MessageService {
+	return &MessageService{messages: messages, cache: cache}
+}
+
+// List returns every message, global and overrides; the translation cache
+// loads from it.
+func (s *MessageService) List(ctx context.Context) ([]message_models.Message, error) {
+	return s.messages.List(ctx)
+}
+
+// Search pages through the global messages.
+func (s *MessageService) Search(ctx context.Context, params query.Params) (query.Page[message_models.Message], error) {
+	return s.messages.Search(ctx, nil, params)
+}
+
+func (s *MessageService) Create(ctx context.Context, message *message_models.Message) error {
+	if err := validate(*message); err != nil {
+		return err
+	}
+	if err := s.messages.Create(ctx, message); err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+// Update replaces the key, value and language of the message with id.
+func (s *MessageService) Update(ctx context.Context, id uint, changes message_models.Message) (*message_models.Message, error) {
+	if err := validate(changes); err != nil {
+		return nil, err
+	}
+	message, err := s.global(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	message.Key, message.Value, message.Lang = changes.Key, changes.Value, changes.Lang
+	if err := s.messages.Update(ctx, message); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return message, nil
+}
+
+func (s *MessageService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.global(ctx, id); err != nil {
+		return err
+	}
+	if err := s.messages.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+/*
+Import creates or updates the messages of lang read from r and returns how
+many were imported. JSON input has the shape of i18n/messages/messages_*.json;
+CSV input has a key,value header row.
+*/
+func (s *MessageService) Import(ctx context.Context, lang, format string, r io.Reader) (int, error) {
+	if lang == "" {
+		return 0, ErrInvalidMessage
+	}
+
+	var messages []message_models.Message
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&messages); err != nil {
+			return 0, fmt.Errorf("%w: invalid JSON: %v", ErrInvalidImport, err)
+		}
+	case FormatCSV:
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid CSV: %v", ErrInvalidImport, err)
+		}
+		for i, record := range records {
+			if i == 0 && strings.EqualFold(record[0], "key") {
+				continue
+			}
+			if len(record) != 2 {
+				return 0, fmt.Errorf("%w: line %d must have a key and a value", ErrInvalidImport, i+1)
+			}
+			messages = append(messages, message_models.Message{Key: record[0], Value: record[1]})
+		}
+	default:
+		return 0, ErrUnsupportedFormat
+	}
+
+	for i := range messages {
+		messages[i].ID = 0
+		messages[i].Lang = lang
+		if err := validate(messages[i]); err != nil {
+			return 0, fmt.Errorf("message %d: %w", i+1, err)
+		}
+	}
+
+	if err := s.messages.Upsert(ctx, messages); err != nil {
+		return 0, err
+	}
+	s.invalidate(ctx)
+	return len(messages), nil
+}
+
+// Export writes the messages of lang to w in the same formats Import reads.
+func (s *MessageService) Export(ctx context.Context, lang, format string, w io.Writer) error {
+	if format != FormatJSON && format != FormatCSV {
+		return ErrUnsupportedFormat
+	}
+
+	messages, err := s.messages.ListByLang(ctx, lang)
+	if err != nil {
+		return err
+	}
+
+	if format == FormatJSON {
+		entries := make([]exportEntry, 0, len(messages))
+		for _, message := range messages {
+			entries = append(entries, exportEntry{Key: message.Key, Value: message.Value})
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(entries)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"key", "value"})
+	for _, message := range messages {
+		writer.Write([]string{message.Key, message.Value})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+type exportEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ListForTenant lists the messages of lang with the tenant's overrides
+// applied. tenantID 0 lists the global messages.
+func (s *MessageService) ListForTenant(ctx context.Context, tenantID uint, lang string) ([]message_models.Message, error) {
+	messages, err := s.messages.ListByLang(ctx, lang)
+	if err != nil || tenantID == 0 {
+		return messages, err
+	}
+
+	overrides, err := s.messages.ListOverrides(ctx, tenantID, lang)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(messages))
+	for i, message := range messages {
+		index[message.Key] = i
+	}
+	for _, override := range overrides {
+		if i, ok := index[override.Key]; ok {
+			messages[i] = override
+		} else {
+			messages = append(messages, override)
+		}
+	}
+	return messages, nil
+}
+
+func (s *MessageService) ListOverrides(ctx context.Context, tenantID uint, params query.Params) (query.Page[message_models.Message], error) {
+	return s.messages.Search(ctx, &tenantID, params)
+}
+
+// SetOverride makes the tenant see value instead of the global message.
+func (s *MessageService) SetOverride(ctx context.Context, tenantID uint, lang, key, value string) (*message_models.Message, error) {
+	message := message_models.NewMessage(key, value, lang)
+	message.TenantID = &tenantID
+	if err := validate(*message); err != nil {
+		return nil, err
+	}
+	if err := s.messages.UpsertOverride(ctx, message); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return message, nil
+}
+
+// DeleteOverride restores the global message for the tenant.
+func (s *MessageService) DeleteOverride(ctx context.Context, tenantID uint, lang, key string) error {
+	if err := s.messages.DeleteOverride(ctx, tenantID, lang, key); err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+// global finds a global message; tenant overrides are managed separately.
+func (s *MessageService) global(ctx context.Context, id uint) (*message_models.Message, error) {
+	message, err := s.messages.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if message.TenantID != nil {
+		return nil, message_repositories.ErrMessageNotFound
+	}
+	return message, nil
+}
+
+// invalidate refreshes the cache. The write already succeeded, so a failure
+// only leaves the cache stale until the next reload and is logged.
+func (s *MessageService) invalidate(ctx context.Context) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Invalidate(ctx); err != nil {
+		logger.FromContext(ctx).Error("Failed to invalidate message cache", zap.Error(err))
+	}
+}
+
+func validate(message message_models.Message) error {
+	if strings.TrimSpace(message.Key) == "" || message.Value == "" || strings.TrimSpace(message.Lang) == "" {
+		return ErrInvalidMessage
+	}
+	return nil
+}
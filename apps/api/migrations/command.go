@@ -0,0 +1,226 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"pengi-med-saas/core/database"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const commandUsage = `Usage: main migrate [--dry-run] <command> [args]
+
+Commands:
+  up [N]         apply all pending migrations, or the next N
+  down [N]       revert the latest migration, or the latest N
+  status         list migrations and whether they are applied
+  redo           revert and re-apply the latest migration
+  create <name>  create empty up/down SQL files in migrations/sql
+  diff <name>    compare the models with the live schema and write the
+                 differences as a new migration for review
+
+Flags:
+  --dry-run      print the SQL instead of executing it (up, down, redo, diff)
+`
+
+var migrationNameSanitizer = regexp.MustCompile(`[^a-z0-9]+`)
+
+/*
+RunCommand runs a migration subcommand, writing its output to out.
+
+connect is only called for commands that need the database, so "create" works
+without one.
+*/
+func RunCommand(args []string, out io.Writer, connect func() (*gorm.DB, error)) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() { fmt.Fprint(out, commandUsage) }
+	dryRun := flags.Bool("dry-run", false, "print the SQL instead of executing it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("missing migrate command")
+	}
+
+	command, rest := flags.Arg(0), flags.Args()[1:]
+	if command == "create" {
+		if len(rest) != 1 {
+			return errors.New("usage: migrate create <name>")
+		}
+		return createMigration(out, rest[0], nil)
+	}
+
+	db, err := connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		migrator = migrator.DryRun(out)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		n, err := parseCount(rest)
+		if err != nil {
+			return err
+		}
+		if *dryRun {
+			fmt.Fprintln(out, "-- dry run: message sync is skipped")
+			_, err := migrator.Up(ctx, n)
+			return err
+		}
+		return runUp(db, n)
+	case "down":
+		n, err := parseCount(rest)
+		if err != nil {
+			return err
+		}
+		reverted, err := migrator.Down(ctx, n)
+		if err == nil && !*dryRun {
+			fmt.Fprintf(out, "Reverted %d migration(s)\n", len(reverted))
+		}
+		return err
+	case "redo":
+		redone, err := migrator.Redo(ctx)
+		if err == nil && redone == nil {
+			fmt.Fprintln(out, "No applied migrations to redo")
+		}
+		return err
+	case "status":
+		return printStatus(ctx, out, migrator)
+	case "diff":
+		if len(rest) != 1 {
+			return errors.New("usage: migrate diff <name>")
+		}
+		return diffMigration(out, db, rest[0], *dryRun)
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
+
+func parseCount(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid migration count %q", args[0])
+	}
+	return n, nil
+}
+
+func printStatus(ctx context.Context, out io.Writer, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		switch {
+		case s.Missing:
+			state = "missing"
+		case s.Modified:
+			state = "modified"
+		case s.Applied:
+			state = "applied"
+		}
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Migration.Version, s.Migration.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
+
+// diffMigration writes the differences between the models and the live schema
+// as a new migration, or prints them when dryRun is set.
+func diffMigration(out io.Writer, db *gorm.DB, name string, dryRun bool) error {
+	diff, err := database.DiffSchema(db, Models()...)
+	if err != nil {
+		return err
+	}
+	if diff.Empty() {
+		fmt.Fprintln(out, "Schema is up to date, no migration created")
+		return nil
+	}
+
+	if dryRun {
+		_, err := fmt.Fprint(out, "-- up\n"+joinStatements(diff.Up)+"\n-- down\n"+joinStatements(diff.Down))
+		return err
+	}
+	return createMigration(out, name, &diff)
+}
+
+func joinStatements(statements []string) string {
+	var b strings.Builder
+	for _, stmt := range statements {
+		b.WriteString(stmt)
+		if !strings.HasPrefix(stmt, "--") {
+			b.WriteString(";")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+/*
+createMigration writes up/down files named after the current UTC time. When
+diff is nil the files are empty templates.
+*/
+func createMigration(out io.Writer, name string, diff *database.SchemaDiff) error {
+	name = strings.Trim(migrationNameSanitizer.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return errors.New("migration name must contain letters or digits")
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting working directory: %w", err)
+	}
+
+	dir := filepath.Join(workDir, "migrations", "sql")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating migrations directory: %w", err)
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(dir, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+		content := fmt.Sprintf("-- %s: %s\n", strings.ToUpper(direction), name)
+		if diff != nil {
+			statements := diff.Up
+			if direction == "down" {
+				statements = diff.Down
+			}
+			content += "-- Generated from the model diff; review before applying.\n" + joinStatements(statements)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("error writing migration file: %w", err)
+		}
+		fmt.Fprintf(out, "Created %s\n", file)
+	}
+	return nil
+}
@@ -1,10 +1,12 @@
 package migrations
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"pengi-med-saas/core/database"
+	"pengi-med-saas/core/logger"
 	company_models "pengi-med-saas/features/companies/models"
 	permission_models "pengi-med-saas/features/permissions/models"
 	tenant_models "pengi-med-saas/features/tenants/models"
@@ -14,10 +16,14 @@ import (
 	"gorm.io/gorm"
 )
 
-func RunMigrations(db *gorm.DB) error {
-	err := database.MigrateDB(
-		db,
-		database.DBExecute{},
+/*
+Models returns the GORM models that make up the schema.
+
+They are no longer auto-migrated on boot: `main migrate diff <name>` compares
+them with the live database and writes a migration file for review.
+*/
+func Models() []any {
+	return []any{
 		tenant_models.Tenant{},
 		permission_models.Permission{},
 		message_models.Message{},
@@ -28,12 +34,31 @@ func RunMigrations(db *gorm.DB) error {
 		user_models.User{},
 		user_models.Environment{},
 		user_models.Role{},
-	)
+	}
+}
+
+func RunMigrations(db *gorm.DB) error {
+	return runMigrations(db, 0)
+}
+
+// runMigrations applies up to n versioned migrations (all of them when n <= 0).
+func runMigrations(db *gorm.DB, n int) error {
+	migrator, err := NewMigrator(db)
 	if err != nil {
 		return err
 	}
 
-	return database.ExecuteAll(db)
+	_, err = migrator.Up(context.Background(), n)
+	return err
+}
+
+// NewMigrator returns a database.Migrator loaded with every registered migration.
+func NewMigrator(db *gorm.DB) (*database.Migrator, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+	return database.NewMigrator(db, logger.Log, all...)
 }
 
 func MigrateMessages(db *gorm.DB, lang string) error {
@@ -55,7 +80,12 @@ func MigrateMessages(db *gorm.DB, lang string) error {
 }
 
 func RunAllMigrations(db *gorm.DB) error {
-	err := RunMigrations(db)
+	return runUp(db, 0)
+}
+
+// runUp runs the schema migrations followed by the message sync.
+func runUp(db *gorm.DB, n int) error {
+	err := runMigrations(db, n)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"embed"
+	"pengi-med-saas/core/database"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// goMigrations holds migrations written in Go, registered from init funcs.
+var goMigrations []database.Migration
+
+// register adds a Go migration. Call it from an init func in this package.
+func register(m database.Migration) {
+	goMigrations = append(goMigrations, m)
+}
+
+// All returns every SQL and Go migration in version order.
+func All() ([]database.Migration, error) {
+	sqlMigrations, err := database.LoadSQLMigrations(sqlFiles, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	all := append(sqlMigrations, goMigrations...)
+	database.SortMigrations(all)
+	return all, nil
+}
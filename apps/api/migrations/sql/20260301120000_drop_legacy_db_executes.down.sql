@@ -0,0 +1,3 @@
+CREATE TABLE IF NOT EXISTS db_executes (
+    id text PRIMARY KEY
+);
@@ -0,0 +1,3 @@
+-- The db_executes table tracked the old map-based migration runner and was
+-- replaced by schema_migrations.
+DROP TABLE IF EXISTS db_executes;
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS "environments";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "role_permissions";
+DROP TABLE IF EXISTS "roles";
+DROP TABLE IF EXISTS "subscriptions";
+DROP TABLE IF EXISTS "companies";
+DROP TABLE IF EXISTS "feature_permissions";
+DROP TABLE IF EXISTS "plan_features";
+DROP TABLE IF EXISTS "features";
+DROP TABLE IF EXISTS "plans";
+DROP TABLE IF EXISTS "messages";
+DROP TABLE IF EXISTS "permissions";
+DROP TABLE IF EXISTS "tenants";
@@ -0,0 +1,159 @@
+-- Schema previously created by GORM AutoMigrate. Every statement is guarded so
+-- databases created before versioned migrations adopt this baseline as-is.
+--
+-- Versioned right after 20260301120000_drop_legacy_db_executes, the latest
+-- migration shipped before it: databases that already applied that one see
+-- the baseline as the next migration rather than an out-of-order one, and
+-- fresh databases apply both in the same order.
+CREATE TABLE IF NOT EXISTS "tenants" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text NOT NULL,
+    "slug" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_tenants_slug" UNIQUE ("slug")
+);
+CREATE INDEX IF NOT EXISTS "idx_tenants_deleted_at" ON "tenants" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "permissions" (
+    "id" varchar(255),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "category" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_permissions_deleted_at" ON "permissions" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "messages" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "key" text NOT NULL,
+    "value" text NOT NULL,
+    "lang" text NOT NULL DEFAULT 'es',
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_key_lang" ON "messages" ("key", "lang");
+CREATE INDEX IF NOT EXISTS "idx_messages_deleted_at" ON "messages" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "plans" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text NOT NULL,
+    "code" text NOT NULL,
+    "price" decimal NOT NULL,
+    "properties" jsonb DEFAULT '{}'::jsonb,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_plans_code" UNIQUE ("code")
+);
+CREATE INDEX IF NOT EXISTS "idx_plans_deleted_at" ON "plans" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "features" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "code" text NOT NULL,
+    "name" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_features_code" UNIQUE ("code")
+);
+CREATE INDEX IF NOT EXISTS "idx_features_deleted_at" ON "features" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "plan_features" (
+    "plan_id" bigint,
+    "feature_id" bigint,
+    PRIMARY KEY ("plan_id", "feature_id"),
+    CONSTRAINT "fk_plan_features_plan" FOREIGN KEY ("plan_id") REFERENCES "plans" ("id"),
+    CONSTRAINT "fk_plan_features_feature" FOREIGN KEY ("feature_id") REFERENCES "features" ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "feature_permissions" (
+    "feature_id" bigint,
+    "permission_id" varchar(255),
+    PRIMARY KEY ("feature_id", "permission_id"),
+    CONSTRAINT "fk_feature_permissions_feature" FOREIGN KEY ("feature_id") REFERENCES "features" ("id"),
+    CONSTRAINT "fk_feature_permissions_permission" FOREIGN KEY ("permission_id") REFERENCES "permissions" ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "companies" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "legal_name" text NOT NULL,
+    "trade_name" text NOT NULL,
+    "plan_code" text NOT NULL,
+    "tenant_id" bigint NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_companies_tenant" FOREIGN KEY ("tenant_id") REFERENCES "tenants" ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_companies_deleted_at" ON "companies" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "subscriptions" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "status" text NOT NULL,
+    "plan_code" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "company_id" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_subscriptions_plan" FOREIGN KEY ("plan_code") REFERENCES "plans" ("code"),
+    CONSTRAINT "fk_companies_subscriptions" FOREIGN KEY ("company_id") REFERENCES "companies" ("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_subscriptions_deleted_at" ON "subscriptions" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "role" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_roles_deleted_at" ON "roles" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "role_permissions" (
+    "role_id" bigint,
+    "permission_id" varchar(255),
+    PRIMARY KEY ("role_id", "permission_id"),
+    CONSTRAINT "fk_role_permissions_role" FOREIGN KEY ("role_id") REFERENCES "roles" ("id"),
+    CONSTRAINT "fk_role_permissions_permission" FOREIGN KEY ("permission_id") REFERENCES "permissions" ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_name" text,
+    "password" text,
+    "email" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "environments" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "name" text,
+    "role_id" bigint,
+    "company_id" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_environments_role" FOREIGN KEY ("role_id") REFERENCES "roles" ("id"),
+    CONSTRAINT "fk_companies_environments" FOREIGN KEY ("company_id") REFERENCES "companies" ("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "fk_users_environments" FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_environments_deleted_at" ON "environments" ("deleted_at");
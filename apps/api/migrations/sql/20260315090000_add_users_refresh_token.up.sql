@@ -0,0 +1,2 @@
+-- Login stores the issued refresh token on the user.
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "refresh_token" text;
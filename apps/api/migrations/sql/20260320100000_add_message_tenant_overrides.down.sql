@@ -0,0 +1,10 @@
+-- Tenant overrides are lost.
+DELETE FROM "messages" WHERE "tenant_id" IS NOT NULL;
+
+DROP INDEX IF EXISTS "idx_messages_tenant_key";
+DROP INDEX IF EXISTS "idx_messages_global_key";
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_key_lang" ON "messages" ("key", "lang");
+
+DROP INDEX IF EXISTS "idx_messages_tenant_id";
+ALTER TABLE "messages" DROP CONSTRAINT IF EXISTS "fk_messages_tenant";
+ALTER TABLE "messages" DROP COLUMN IF EXISTS "tenant_id";
@@ -0,0 +1,10 @@
+-- Messages can be overridden per tenant. Global messages keep tenant_id NULL
+-- and stay unique per key and language; overrides are unique per tenant.
+ALTER TABLE "messages" ADD COLUMN IF NOT EXISTS "tenant_id" bigint;
+ALTER TABLE "messages" ADD CONSTRAINT "fk_messages_tenant"
+    FOREIGN KEY ("tenant_id") REFERENCES "tenants" ("id") ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS "idx_messages_tenant_id" ON "messages" ("tenant_id");
+
+DROP INDEX IF EXISTS "idx_key_lang";
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_messages_global_key" ON "messages" ("key", "lang") WHERE "tenant_id" IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_messages_tenant_key" ON "messages" ("tenant_id", "key", "lang") WHERE "tenant_id" IS NOT NULL;
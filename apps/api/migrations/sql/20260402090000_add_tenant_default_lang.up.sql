@@ -0,0 +1,3 @@
+-- Language used when a request's Accept-Language matches no available
+-- language. Empty means the I18N_DEFAULT_LANG setting.
+ALTER TABLE "tenants" ADD COLUMN IF NOT EXISTS "default_lang" text NOT NULL DEFAULT '';
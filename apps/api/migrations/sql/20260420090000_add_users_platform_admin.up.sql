@@ -0,0 +1,4 @@
+-- Platform admins run the service itself, e.g. the global messages every
+-- tenant is served. It is granted by hand, never through a company role:
+--   UPDATE users SET platform_admin = true WHERE user_name = '...';
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "platform_admin" boolean NOT NULL DEFAULT false;
@@ -0,0 +1,115 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	company_models "pengi-med-saas/features/companies/models"
+	"pengi-med-saas/testutil"
+	"reflect"
+	"testing"
+)
+
+func tradeNames(companies []company_models.Company) []string {
+	names := make([]string, len(companies))
+	for i, company := range companies {
+		names[i] = company.TradeName
+	}
+	return names
+}
+
+func TestGetCompanies(t *testing.T) {
+	client := testutil.NewClient(t, newMemoryServer(t))
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Acme", "Globex"}},
+		{"sort=-trade_name", []string{"Globex", "Acme"}},
+		{"tenant_id=2", []string{"Globex"}},
+		{"trade_name[like]=acm", []string{"Acme"}},
+		{"plan_code[in]=basic,enterprise", []string{"Acme"}},
+		{"offset=1", []string{"Globex"}},
+		{"tenant_id=3", []string{}},
+	}
+	for _, tt := range tests {
+		res := client.Get("/api/companies?" + tt.query)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("%q: status = %d, want 200: %s", tt.query, res.StatusCode, res.Body)
+		}
+		var companies []company_models.Company
+		res.Decode(&companies)
+		if got := tradeNames(companies); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: companies = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestGetCompaniesCursor(t *testing.T) {
+	client := testutil.NewClient(t, newMemoryServer(t))
+
+	res := client.Get("/api/companies?limit=1")
+	var companies []company_models.Company
+	env := res.Decode(&companies)
+	if env.Meta == nil || env.Meta.Total != 2 || env.Meta.NextCursor == "" {
+		t.Fatalf("meta = %+v, want total 2 and a next cursor", env.Meta)
+	}
+	if got := tradeNames(companies); !reflect.DeepEqual(got, []string{"Acme"}) {
+		t.Fatalf("first page = %v", got)
+	}
+	if link := res.Header.Get("Link"); link == "" {
+		t.Error("no Link header on a page with a next cursor")
+	}
+
+	res = client.Get("/api/companies?limit=1&cursor=" + url.QueryEscape(env.Meta.NextCursor))
+	env = res.Decode(&companies)
+	if got := tradeNames(companies); !reflect.DeepEqual(got, []string{"Globex"}) {
+		t.Errorf("second page = %v", got)
+	}
+	if env.Meta.NextCursor != "" {
+		t.Errorf("last page has next cursor %q", env.Meta.NextCursor)
+	}
+}
+
+func TestGetCompaniesInvalidQuery(t *testing.T) {
+	client := testutil.NewClient(t, newMemoryServer(t))
+
+	for _, query := range []string{
+		"tenant_id=abc",
+		"sort=tenant_id", // filterable, not sortable
+		"legal_name[gte]=A",
+		"secret[eq]=1",
+		"limit=0",
+		"cursor=not-a-cursor",
+		"cursor=WyIxIl0&offset=1",
+	} {
+		res := client.Get("/api/companies?" + query)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want 400: %s", query, res.StatusCode, res.Body)
+		}
+	}
+}
+
+func TestListFixtureCompanies(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	fixtures := testutil.LoadDefaultFixtures(t, db)
+	client := testutil.NewClient(t, testutil.NewServer(t, db)).
+		WithTenant("acme").
+		Login("alice", fixtures.Passwords["alice"])
+
+	var companies []company_models.Company
+	env := client.Get("/api/companies?sort=trade_name").Decode(&companies)
+	if got := tradeNames(companies); !reflect.DeepEqual(got, []string{"Acme", "Globex"}) {
+		t.Errorf("companies = %v, want the fixture companies", got)
+	}
+	if env.Meta == nil || env.Meta.Total != int64(len(fixtures.Companies)) {
+		t.Errorf("meta = %+v, want total %d", env.Meta, len(fixtures.Companies))
+	}
+
+	globex := fixtures.Tenants["globex"]
+	client.Get(fmt.Sprintf("/api/companies?tenant_id=%d", globex.ID)).Decode(&companies)
+	if len(companies) != 1 || companies[0].ID != fixtures.Companies["Globex"].ID || companies[0].TenantID != globex.ID {
+		t.Errorf("globex companies = %+v, want only Globex", companies)
+	}
+}
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"pengi-med-saas/core/auth"
+	"pengi-med-saas/core/config"
+	"pengi-med-saas/features/health"
+	"time"
+)
+
+// Config holds the settings the services and the router are built with.
+type Config struct {
+	Auth auth.Config
+	// DefaultLang answers requests that ask for no known language.
+	DefaultLang string
+	// HealthCheckTimeout bounds each readiness check.
+	HealthCheckTimeout time.Duration
+}
+
+/*
+LoadConfig reads the routes configuration from environment variables:
+  - the auth.LoadConfig variables
+  - I18N_DEFAULT_LANG ("es")
+  - HEALTH_CHECK_TIMEOUT (2s)
+
+All invalid values are reported together.
+*/
+func LoadConfig() (Config, error) {
+	var l config.Loader
+	authConfig, err := auth.LoadConfig()
+	l.Add(err)
+
+	cfg := Config{
+		Auth:               authConfig,
+		DefaultLang:        l.String("I18N_DEFAULT_LANG", "es"),
+		HealthCheckTimeout: l.Duration("HEALTH_CHECK_TIMEOUT", health.DefaultTimeout),
+	}
+	l.Check(cfg.HealthCheckTimeout > 0, "HEALTH_CHECK_TIMEOUT must be positive")
+	return cfg, l.Err()
+}
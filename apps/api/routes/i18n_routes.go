@@ -2,18 +2,53 @@ package routes
 
 import (
 	"pengi-med-saas/core/envelope"
+	tenant_middleware "pengi-med-saas/features/tenants/middleware"
+	auth_middleware "pengi-med-saas/features/users/middleware"
 	i18n_handlers "pengi-med-saas/i18n/handlers"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func RegisterI18nRoutes(router *gin.RouterGroup, db *gorm.DB) {
-	i18nHandler := i18n_handlers.NewMessageHandler(db)
+func RegisterI18nRoutes(router *gin.RouterGroup, services Services) {
+	i18nHandler := i18n_handlers.NewMessageHandler(services.Messages)
 
 	group := router.Group("/i18n")
 	{
 		group.GET("/messages", envelope.Handle(i18nHandler.GetAllMessages))
+		group.GET("/messages/changes", envelope.Handle(i18nHandler.GetMessageChanges))
 		group.GET("/version", envelope.Handle(i18nHandler.GetMessageVersion))
+		group.GET("/missing",
+			auth_middleware.AuthMiddleware(services.Tokens),
+			auth_middleware.RequirePlatformAdmin(services.Users),
+			envelope.Handle(i18nHandler.GetMissingMessages),
+		)
+	}
+
+	// Global messages are shared by every tenant: platform admins only. Tenant
+	// admins customize theirs through the overrides below.
+	adminGroup := group.Group("/admin/messages",
+		auth_middleware.AuthMiddleware(services.Tokens),
+		auth_middleware.RequirePlatformAdmin(services.Users),
+	)
+	{
+		adminGroup.GET("", envelope.Handle(i18nHandler.ListMessages))
+		adminGroup.POST("", envelope.Handle(i18nHandler.CreateMessage))
+		adminGroup.PUT("/:id", envelope.Handle(i18nHandler.UpdateMessage))
+		adminGroup.DELETE("/:id", envelope.Handle(i18nHandler.DeleteMessage))
+		adminGroup.POST("/import", envelope.Handle(i18nHandler.ImportMessages))
+		adminGroup.GET("/export", i18nHandler.ExportMessages)
+	}
+
+	// Tenant admins customise wording for their own tenant (X-Tenant-Slug); the
+	// caller must hold the admin role in one of that tenant's companies.
+	tenantGroup := router.Group("/tenant/messages",
+		auth_middleware.AuthMiddleware(services.Tokens),
+		tenant_middleware.TenantMiddleware(services.Tenants),
+		auth_middleware.RequireTenantAdmin(services.Users, services.Companies),
+	)
+	{
+		tenantGroup.GET("", envelope.Handle(i18nHandler.ListOverrides))
+		tenantGroup.PUT("/:lang/:key", envelope.Handle(i18nHandler.SetOverride))
+		tenantGroup.DELETE("/:lang/:key", envelope.Handle(i18nHandler.DeleteOverride))
 	}
 }
@@ -0,0 +1,326 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	message_models "pengi-med-saas/i18n/models"
+	"pengi-med-saas/testutil"
+	"testing"
+)
+
+func TestGlobalMessagesRequirePlatformAdmin(t *testing.T) {
+	server := newMemoryServer(t)
+	message := map[string]string{"key": "greeting", "value": "Hola", "lang": "es"}
+
+	tests := []struct {
+		user string
+		want int
+	}{
+		{"alice", http.StatusForbidden}, // admin of a company, not of the platform
+		{"carol", http.StatusForbidden},
+		{"bob", http.StatusForbidden},
+		{"root", http.StatusOK},
+	}
+	for _, tt := range tests {
+		client := testutil.NewClient(t, server).Login(tt.user, tt.user+"-password")
+
+		for _, res := range []*testutil.Response{
+			client.Get("/api/i18n/admin/messages"),
+			client.Get("/api/i18n/missing"),
+		} {
+			if res.StatusCode != tt.want {
+				t.Errorf("%s: status = %d, want %d: %s", tt.user, res.StatusCode, tt.want, res.Body)
+			}
+		}
+		if tt.want == http.StatusForbidden {
+			if res := client.Post("/api/i18n/admin/messages", message); res.StatusCode != http.StatusForbidden {
+				t.Errorf("%s created a global message: status = %d: %s", tt.user, res.StatusCode, res.Body)
+			}
+		}
+	}
+
+	root := testutil.NewClient(t, server).Login("root", "root-password")
+	if res := root.Post("/api/i18n/admin/messages", message); res.StatusCode != http.StatusCreated {
+		t.Errorf("root: status = %d, want 201: %s", res.StatusCode, res.Body)
+	}
+}
+
+func TestTenantMessagesRequireTenantAdmin(t *testing.T) {
+	server := newMemoryServer(t)
+	alice := testutil.NewClient(t, server).Login("alice", "alice-password")
+	carol := testutil.NewClient(t, server).Login("carol", "carol-password")
+	bob := testutil.NewClient(t, server).Login("bob", "bob-password")
+
+	tests := []struct {
+		name   string
+		client *testutil.Client
+		tenant string
+		want   int
+	}{
+		{"admin of an acme company", alice, "acme", http.StatusOK},
+		{"admin of another tenant's company", alice, "globex", http.StatusForbidden},
+		{"staff of an acme company", carol, "acme", http.StatusForbidden},
+		{"admin of a globex company", carol, "globex", http.StatusOK},
+		{"staff only", bob, "acme", http.StatusForbidden},
+		{"unknown tenant", alice, "initech", http.StatusNotFound},
+		{"no tenant", alice, "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tt.client
+			if tt.tenant != "" {
+				client = client.WithTenant(tt.tenant)
+			}
+			if res := client.Get("/api/tenant/messages"); res.StatusCode != tt.want {
+				t.Errorf("GET: status = %d, want %d: %s", res.StatusCode, tt.want, res.Body)
+			}
+			res := client.Put("/api/tenant/messages/es/greeting", map[string]string{"value": "Hola"})
+			if res.StatusCode != tt.want {
+				t.Errorf("PUT: status = %d, want %d: %s", res.StatusCode, tt.want, res.Body)
+			}
+		})
+	}
+}
+
+func TestGlobalMessagesAdmin(t *testing.T) {
+	server := newMemoryServer(t, message_models.Message{Key: "greeting", Value: "Hola", Lang: "es"})
+	root := testutil.NewClient(t, server).Login("root", "root-password")
+
+	res := root.Post("/api/i18n/admin/messages", map[string]string{"key": "farewell", "value": "Adiós", "lang": "es"})
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201: %s", res.StatusCode, res.Body)
+	}
+	var created message_models.Message
+	res.Decode(&created)
+
+	var messages []message_models.Message
+	res = root.Get("/api/i18n/admin/messages?lang=es&sort=key")
+	if env := res.Decode(&messages); env.Meta == nil || env.Meta.Total != 2 {
+		t.Fatalf("list: meta = %+v, want 2 messages: %s", env.Meta, res.Body)
+	}
+	if messages[0].Key != "farewell" || messages[1].Key != "greeting" {
+		t.Errorf("list = %+v, want farewell and greeting", messages)
+	}
+
+	path := fmt.Sprintf("/api/i18n/admin/messages/%d", created.ID)
+	res = root.Put(path, map[string]string{"key": "farewell", "value": "Hasta luego", "lang": "es"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("update: status = %d, want 200: %s", res.StatusCode, res.Body)
+	}
+	if got := publicMessages(t, testutil.NewClient(t, server), "es")["farewell"]; got != "Hasta luego" {
+		t.Errorf("served farewell = %q after the update, want %q", got, "Hasta luego")
+	}
+
+	if res := root.Delete(path); res.StatusCode != http.StatusOK {
+		t.Fatalf("delete: status = %d, want 200: %s", res.StatusCode, res.Body)
+	}
+	if _, ok := publicMessages(t, testutil.NewClient(t, server), "es")["farewell"]; ok {
+		t.Error("farewell is still served after the delete")
+	}
+
+	for _, tt := range []struct {
+		method, path string
+		body         any
+		want         int
+	}{
+		{http.MethodPut, path, map[string]string{"key": "farewell", "value": "x", "lang": "es"}, http.StatusNotFound},
+		{http.MethodDelete, path, nil, http.StatusNotFound},
+		{http.MethodPut, "/api/i18n/admin/messages/abc", map[string]string{"key": "k", "value": "v", "lang": "es"}, http.StatusBadRequest},
+		{http.MethodPost, "/api/i18n/admin/messages", map[string]string{"key": "no_value", "lang": "es"}, http.StatusBadRequest},
+		{http.MethodGet, "/api/i18n/admin/messages?lang[like]=e", nil, http.StatusBadRequest},
+	} {
+		if res := root.Do(tt.method, tt.path, tt.body); res.StatusCode != tt.want {
+			t.Errorf("%s %s: status = %d, want %d: %s", tt.method, tt.path, res.StatusCode, tt.want, res.Body)
+		}
+	}
+}
+
+func TestTenantOverrides(t *testing.T) {
+	server := newMemoryServer(t,
+		message_models.Message{Key: "greeting", Value: "Hola", Lang: "es"},
+		message_models.Message{Key: "greeting", Value: "Hello", Lang: "en"},
+	)
+	alice := testutil.NewClient(t, server).Login("alice", "alice-password").WithTenant("acme")
+	acme := testutil.NewClient(t, server).WithTenant("acme")
+	globex := testutil.NewClient(t, server).WithTenant("globex")
+
+	res := alice.Put("/api/tenant/messages/es/greeting", map[string]string{"value": "Bienvenido a Acme"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("set: status = %d, want 200: %s", res.StatusCode, res.Body)
+	}
+	if got := publicMessages(t, acme, "es")["greeting"]; got != "Bienvenido a Acme" {
+		t.Errorf("acme es greeting = %q, want the override", got)
+	}
+	if got := publicMessages(t, acme, "en")["greeting"]; got != "Hello" {
+		t.Errorf("acme en greeting = %q, want the global message", got)
+	}
+	if got := publicMessages(t, globex, "es")["greeting"]; got != "Hola" {
+		t.Errorf("globex es greeting = %q, want the global message", got)
+	}
+
+	var overrides []message_models.Message
+	alice.Get("/api/tenant/messages").Decode(&overrides)
+	if len(overrides) != 1 || overrides[0].Value != "Bienvenido a Acme" {
+		t.Errorf("overrides = %+v, want the acme greeting", overrides)
+	}
+
+	if res := alice.Put("/api/tenant/messages/es/greeting", map[string]string{}); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("empty value: status = %d, want 400: %s", res.StatusCode, res.Body)
+	}
+
+	if res := alice.Delete("/api/tenant/messages/es/greeting"); res.StatusCode != http.StatusOK {
+		t.Fatalf("delete: status = %d, want 200: %s", res.StatusCode, res.Body)
+	}
+	if got := publicMessages(t, acme, "es")["greeting"]; got != "Hola" {
+		t.Errorf("acme es greeting = %q after the delete, want the global message", got)
+	}
+}
+
+func TestGetMessagesETag(t *testing.T) {
+	server := newMemoryServer(t, message_models.Message{Key: "greeting", Value: "Hola", Lang: "es"})
+	client := testutil.NewClient(t, server).WithTenant("acme")
+
+	res := client.Get("/api/i18n/messages?lang=es")
+	etag := res.Header.Get("ETag")
+	if res.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag = %q; want 200 with an ETag", res.StatusCode, etag)
+	}
+	if vary := res.Header.Get("Vary"); vary != "Accept-Language, X-Tenant-Slug" {
+		t.Errorf("Vary = %q", vary)
+	}
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"stale", ` + etag, "*"} {
+		res := client.WithHeader("If-None-Match", ifNoneMatch).Get("/api/i18n/messages?lang=es")
+		if res.StatusCode != http.StatusNotModified || len(res.Body) != 0 {
+			t.Errorf("If-None-Match %s: status = %d, want an empty 304: %s", ifNoneMatch, res.StatusCode, res.Body)
+		}
+	}
+	if res := client.WithHeader("If-None-Match", `"stale"`).Get("/api/i18n/messages?lang=es"); res.StatusCode != http.StatusOK {
+		t.Errorf("stale ETag: status = %d, want 200", res.StatusCode)
+	}
+
+	// An acme override changes what acme is served, so its ETag.
+	alice := testutil.NewClient(t, server).Login("alice", "alice-password").WithTenant("acme")
+	if res := alice.Put("/api/tenant/messages/es/greeting", map[string]string{"value": "Hola Acme"}); res.StatusCode != http.StatusOK {
+		t.Fatalf("set override: status = %d: %s", res.StatusCode, res.Body)
+	}
+	res = client.WithHeader("If-None-Match", etag).Get("/api/i18n/messages?lang=es")
+	if res.StatusCode != http.StatusOK || res.Header.Get("ETag") == etag {
+		t.Errorf("after an override: status = %d, ETag = %q; want 200 with a new ETag", res.StatusCode, res.Header.Get("ETag"))
+	}
+
+	// globex still gets the global messages, so the first ETag still holds.
+	globex := testutil.NewClient(t, server).WithTenant("globex").WithHeader("If-None-Match", etag)
+	if res := globex.Get("/api/i18n/messages?lang=es"); res.StatusCode != http.StatusNotModified {
+		t.Errorf("globex: status = %d, want 304", res.StatusCode)
+	}
+}
+
+// publicMessages returns the messages GET /api/i18n/messages serves for lang,
+// by key.
+func publicMessages(t *testing.T, client *testutil.Client, lang string) map[string]string {
+	t.Helper()
+	res := client.Get("/api/i18n/messages?lang=" + lang)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET /api/i18n/messages: status = %d: %s", res.StatusCode, res.Body)
+	}
+	var messages []message_models.Message
+	res.Decode(&messages)
+	values := make(map[string]string, len(messages))
+	for _, message := range messages {
+		values[message.Key] = message.Value
+	}
+	return values
+}
+
+// overrideKey is a global message the migrations load from i18n/messages.
+const overrideKey = "pengi.copywright"
+
+func TestFixtureTenantOverrides(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	fixtures := testutil.LoadDefaultFixtures(t, db)
+	server := testutil.NewServer(t, db)
+	path := "/api/tenant/messages/es/" + overrideKey
+	override := map[string]string{"value": "Acme Health"}
+
+	alice := testutil.NewClient(t, server).Login("alice", fixtures.Passwords["alice"])
+	if res := alice.WithTenant("globex").Put(path, override); res.StatusCode != http.StatusForbidden {
+		t.Errorf("alice on globex: status = %d, want 403: %s", res.StatusCode, res.Body)
+	}
+	if res := alice.WithTenant("acme").Put(path, override); res.StatusCode != http.StatusOK {
+		t.Fatalf("alice on acme: status = %d, want 200: %s", res.StatusCode, res.Body)
+	}
+
+	carol := testutil.NewClient(t, server).Login("carol", fixtures.Passwords["carol"])
+	if res := carol.WithTenant("acme").Delete(path); res.StatusCode != http.StatusForbidden {
+		t.Errorf("carol, staff on acme: status = %d, want 403: %s", res.StatusCode, res.Body)
+	}
+	if res := carol.WithTenant("globex").Get("/api/tenant/messages"); res.StatusCode != http.StatusOK {
+		t.Errorf("carol on globex: status = %d, want 200: %s", res.StatusCode, res.Body)
+	}
+
+	acme := publicMessages(t, testutil.NewClient(t, server).WithTenant("acme"), "es")
+	if acme[overrideKey] != "Acme Health" {
+		t.Errorf("acme %s = %q, want the override", overrideKey, acme[overrideKey])
+	}
+	globex := publicMessages(t, testutil.NewClient(t, server).WithTenant("globex"), "es")
+	if globex[overrideKey] == "" || globex[overrideKey] == "Acme Health" {
+		t.Errorf("globex %s = %q, want the global message", overrideKey, globex[overrideKey])
+	}
+
+	var overrides []message_models.Message
+	alice.WithTenant("acme").Get("/api/tenant/messages").Decode(&overrides)
+	if len(overrides) != 1 || overrides[0].TenantID == nil || *overrides[0].TenantID != fixtures.Tenants["acme"].ID {
+		t.Errorf("acme overrides = %+v, want the one set", overrides)
+	}
+}
+
+func TestFixtureTenantDefaultLanguage(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	testutil.LoadDefaultFixtures(t, db)
+	server := testutil.NewServer(t, db)
+
+	tests := []struct {
+		tenant string
+		want   string
+	}{
+		{"globex", "en"}, // the tenant's default_lang
+		{"acme", testutil.Config.DefaultLang},
+		{"", testutil.Config.DefaultLang},
+	}
+	for _, tt := range tests {
+		client := testutil.NewClient(t, server).WithHeader("Accept-Language", "de-DE")
+		if tt.tenant != "" {
+			client = client.WithTenant(tt.tenant)
+		}
+		res := client.Get("/api/i18n/messages")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("%q: status = %d, want 200: %s", tt.tenant, res.StatusCode, res.Body)
+		}
+		if got := res.Header.Get("Content-Language"); got != tt.want {
+			t.Errorf("%q: Content-Language = %q, want %q", tt.tenant, got, tt.want)
+		}
+	}
+}
+
+func TestFixtureGlobalMessages(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	fixtures := testutil.LoadDefaultFixtures(t, db)
+	server := testutil.NewServer(t, db)
+	message := map[string]string{"key": "fixture.greeting", "value": "Hola", "lang": "es"}
+
+	alice := testutil.NewClient(t, server).WithTenant("acme").Login("alice", fixtures.Passwords["alice"])
+	if res := alice.Post("/api/i18n/admin/messages", message); res.StatusCode != http.StatusForbidden {
+		t.Errorf("alice: status = %d, want 403: %s", res.StatusCode, res.Body)
+	}
+
+	root := testutil.NewClient(t, server).Login("root", fixtures.Passwords["root"])
+	if res := root.Post("/api/i18n/admin/messages", message); res.StatusCode != http.StatusCreated {
+		t.Fatalf("root: status = %d, want 201: %s", res.StatusCode, res.Body)
+	}
+	for _, tenant := range []string{"acme", "globex"} {
+		if got := publicMessages(t, testutil.NewClient(t, server).WithTenant(tenant), "es")["fixture.greeting"]; got != "Hola" {
+			t.Errorf("%s: fixture.greeting = %q, want the new global message", tenant, got)
+		}
+	}
+}
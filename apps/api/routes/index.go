@@ -5,9 +5,10 @@ import (
 	"gorm.io/gorm"
 )
 
-// RegisterRoutes registers all the routes for /api/**/*
-func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
-	RegisterI18nRoutes(router, db)
-	RegisterCompanyRoutes(router, db)
-	RegisterUserRoutes(router, db)
+// RegisterRoutes registers all the routes for /api/**/*. db is only used to
+// open per-request transactions; a nil db runs those routes without one.
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB, services Services) {
+	RegisterI18nRoutes(router, services)
+	RegisterCompanyRoutes(router, services)
+	RegisterUserRoutes(router, db, services)
 }
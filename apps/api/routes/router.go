@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"pengi-med-saas/core/database"
+	"pengi-med-saas/core/envelope"
+	"pengi-med-saas/core/logger"
+	"pengi-med-saas/core/telemetry"
+	"pengi-med-saas/features/health"
+	i18n_middleware "pengi-med-saas/i18n/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewRouter builds the HTTP engine with the global middleware, /health and
+// every /api route.
+func NewRouter(db *gorm.DB, services Services, cfg Config, tel *telemetry.Telemetry) *gin.Engine {
+	r := gin.New()
+	r.Use(tel.Middleware(), logger.RequestLogger(), envelope.Recovery())
+
+	r.Use(database.ReplicaMiddleware())
+	r.Use(i18n_middleware.I18nMiddleware(services.MessageCache, services.Tenants, cfg.DefaultLang))
+
+	r.GET("/health", health.Health)
+	r.GET("/health/live", health.Live)
+	r.GET("/health/ready", health.Ready(services.Health))
+	if metrics := tel.MetricsHandler(); metrics != nil {
+		r.GET("/metrics", gin.WrapH(metrics))
+	}
+
+	RegisterRoutes(r.Group("/api"), db, services)
+	return r
+}
@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pengi-med-saas/core/auth"
+	"pengi-med-saas/core/database"
+	company_repositories "pengi-med-saas/features/companies/repositories"
+	company_services "pengi-med-saas/features/companies/services"
+	"pengi-med-saas/features/health"
+	tenant_repositories "pengi-med-saas/features/tenants/repositories"
+	tenant_services "pengi-med-saas/features/tenants/services"
+	user_repositories "pengi-med-saas/features/users/repositories"
+	user_services "pengi-med-saas/features/users/services"
+	message_cache "pengi-med-saas/i18n/cache"
+	message_repositories "pengi-med-saas/i18n/repositories"
+	message_services "pengi-med-saas/i18n/services"
+	"pengi-med-saas/migrations"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+Services holds the services the HTTP handlers depend on. NewServices backs
+them with Postgres; tests build them on top of the in-memory repositories
+instead.
+*/
+type Services struct {
+	Users     *user_services.UserService
+	Companies *company_services.CompanyService
+	Tenants   *tenant_services.TenantService
+	Messages  *message_services.MessageService
+	// MessageCache reloads translations when messages change; main runs its
+	// Listen loop so changes made through other instances are picked up.
+	MessageCache *message_cache.Sync
+	// Health decides readiness; features register the services they need.
+	Health *health.Checker
+	// Tokens signs and verifies the JWTs used by the auth routes and middleware.
+	Tokens *auth.Tokens
+}
+
+func NewServices(db *gorm.DB, cfg Config) Services {
+	messages := message_repositories.NewGormMessageRepository(db)
+	// The cache reloads right after writes, so it reads from the primary.
+	messageCache := message_cache.NewSync(db, message_repositories.NewGormMessageRepository(database.Primary(db)))
+
+	return Services{
+		Users:     user_services.NewUserService(user_repositories.NewGormUserRepository(db)),
+		Companies: company_services.NewCompanyService(company_repositories.NewGormCompanyRepository(db)),
+		Tenants:   tenant_services.NewTenantService(tenant_repositories.NewGormTenantRepository(db)),
+		Messages:  message_services.NewMessageService(messages, messageCache),
+
+		MessageCache: messageCache,
+		Health:       newHealthChecker(db, cfg.HealthCheckTimeout),
+		Tokens:       auth.NewTokens(cfg.Auth),
+	}
+}
+
+/*
+newHealthChecker checks what this instance needs to serve requests: the
+primary database, a schema with every migration applied (deploys with
+DB_AUTO_MIGRATE=false may run ahead of `main migrate up`) and translations
+loaded. timeout bounds each check.
+*/
+func newHealthChecker(db *gorm.DB, timeout time.Duration) *health.Checker {
+	checker := health.NewChecker(timeout)
+
+	checker.Register("database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+
+	newMigrator := sync.OnceValues(func() (*database.Migrator, error) {
+		return migrations.NewMigrator(db)
+	})
+	checker.Register("migrations", func(ctx context.Context) error {
+		migrator, err := newMigrator()
+		if err != nil {
+			return err
+		}
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			ids := make([]string, len(pending))
+			for i, migration := range pending {
+				ids[i] = migration.ID()
+			}
+			return fmt.Errorf("%d pending migrations: %s", len(pending), strings.Join(ids, ", "))
+		}
+		return nil
+	})
+
+	checker.Register("i18n_cache", func(context.Context) error {
+		if !message_cache.Loaded() {
+			return errors.New("messages not loaded")
+		}
+		return nil
+	})
+	return checker
+}
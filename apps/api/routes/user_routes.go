@@ -2,15 +2,15 @@ package routes
 
 import (
 	"pengi-med-saas/core/envelope"
-	"pengi-med-saas/core/logger"
 	user_handlers "pengi-med-saas/features/users/handlers"
+	auth_middleware "pengi-med-saas/features/users/middleware"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func RegisterUserRoutes(router *gin.RouterGroup, db *gorm.DB) {
-	userHandler := user_handlers.NewUserHandler(db, logger.Log)
+func RegisterUserRoutes(router *gin.RouterGroup, db *gorm.DB, services Services) {
+	userHandler := user_handlers.NewUserHandler(services.Users, services.Tokens)
 
 	userRoutes := router.Group("/users")
 	{
@@ -19,10 +19,10 @@ func RegisterUserRoutes(router *gin.RouterGroup, db *gorm.DB) {
 
 	authRoutes := router.Group("/auth")
 	{
-		authRoutes.POST("/signup", envelope.Handle(userHandler.SignUp))
-		authRoutes.POST("/login", envelope.Handle(userHandler.Login))
+		authRoutes.POST("/signup", envelope.HandleTx(db, userHandler.SignUp))
+		authRoutes.POST("/login", envelope.HandleTx(db, userHandler.Login))
 		authRoutes.POST("/refresh", envelope.Handle(userHandler.RefreshAuthToken))
-		authRoutes.POST("/extend", envelope.Handle(userHandler.ExtendSession))
+		authRoutes.POST("/extend", auth_middleware.AuthMiddleware(services.Tokens), envelope.Handle(userHandler.ExtendSession))
 		authRoutes.POST("/validate", envelope.Handle(userHandler.ValidateBearerToken))
 	}
 
@@ -0,0 +1,86 @@
+package routes_test
+
+import (
+	"net/http"
+	user_models "pengi-med-saas/features/users/models"
+	"pengi-med-saas/testutil"
+	"slices"
+	"testing"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+func TestListUsers(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	fixtures := testutil.LoadDefaultFixtures(t, db)
+
+	client := testutil.NewClient(t, testutil.NewServer(t, db)).
+		WithTenant("acme").
+		Login("alice", fixtures.Passwords["alice"])
+
+	res := client.Get("/api/users")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", res.StatusCode, res.Body)
+	}
+
+	var users []user_models.User
+	res.Decode(&users)
+	for name := range fixtures.Users {
+		if !slices.ContainsFunc(users, func(user user_models.User) bool { return user.UserName == name }) {
+			t.Errorf("users = %+v, missing %s", users, name)
+		}
+	}
+}
+
+func TestLoginFixtureUsers(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	fixtures := testutil.LoadDefaultFixtures(t, db)
+	server := testutil.NewServer(t, db)
+
+	for name, user := range fixtures.Users {
+		client := testutil.NewClient(t, server).WithTenant("acme")
+
+		res := client.Post("/api/auth/login", map[string]string{"user_name": name, "password": fixtures.Passwords[name]})
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("login as %s: status = %d, want 200: %s", name, res.StatusCode, res.Body)
+		}
+		var data struct {
+			Token  string `json:"token"`
+			UserID uint   `json:"user_id"`
+		}
+		res.Decode(&data)
+		if data.Token == "" || data.UserID != user.ID {
+			t.Errorf("login as %s: data = %+v, want a token for user %d", name, data, user.ID)
+		}
+
+		res = client.Post("/api/auth/login", map[string]string{"user_name": name, "password": "wrong-password"})
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Errorf("login as %s with a wrong password: status = %d, want 401", name, res.StatusCode)
+		}
+	}
+}
+
+func TestSignUpThenLogin(t *testing.T) {
+	db := testutil.NewDatabase(t)
+	testutil.LoadDefaultFixtures(t, db)
+	client := testutil.NewClient(t, testutil.NewServer(t, db)).WithTenant("acme")
+
+	res := client.Post("/api/auth/signup", map[string]string{
+		"user_name": "dave",
+		"password":  "dave-password",
+		"email":     "dave@acme.test",
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("signup: status = %d, want 200: %s", res.StatusCode, res.Body)
+	}
+
+	client.Login("dave", "dave-password")
+	if res := client.Post("/api/auth/extend", nil); res.StatusCode != http.StatusOK {
+		t.Errorf("extend: status = %d, want 200: %s", res.StatusCode, res.Body)
+	}
+
+	// The new user administers no company, so tenant routes stay closed.
+	if res := client.Get("/api/tenant/messages"); res.StatusCode != http.StatusForbidden {
+		t.Errorf("tenant messages: status = %d, want 403: %s", res.StatusCode, res.Body)
+	}
+}
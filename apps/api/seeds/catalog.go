@@ -0,0 +1,67 @@
+package seeds
+
+// The catalog is the same in every seeded world; only tenants, companies and
+// users depend on the seed value.
+
+type permissionSeed struct {
+	ID       string
+	Name     string
+	Category string
+}
+
+var permissions = []permissionSeed{
+	{"users.read", "View users", "users"},
+	{"users.write", "Manage users", "users"},
+	{"companies.read", "View companies", "companies"},
+	{"companies.write", "Manage companies", "companies"},
+	{"billing.read", "View billing", "billing"},
+	{"billing.write", "Manage billing", "billing"},
+	{"reports.read", "View reports", "reports"},
+	{"settings.write", "Manage settings", "settings"},
+}
+
+type featureSeed struct {
+	Code        string
+	Name        string
+	Permissions []string
+}
+
+var features = []featureSeed{
+	{"user_management", "User management", []string{"users.read", "users.write"}},
+	{"company_management", "Company management", []string{"companies.read", "companies.write"}},
+	{"billing", "Billing", []string{"billing.read", "billing.write"}},
+	{"reports", "Reports", []string{"reports.read"}},
+	{"settings", "Settings", []string{"settings.write"}},
+}
+
+type planSeed struct {
+	Code     string
+	Name     string
+	Price    float64
+	Features []string
+}
+
+var plans = []planSeed{
+	{"basic", "Basic", 29, []string{"user_management", "company_management"}},
+	{"pro", "Professional", 79, []string{"user_management", "company_management", "billing", "reports"}},
+	{"enterprise", "Enterprise", 199, []string{"user_management", "company_management", "billing", "reports", "settings"}},
+}
+
+type roleSeed struct {
+	Role        string
+	Permissions []string
+}
+
+var roles = []roleSeed{
+	{"admin", []string{"users.read", "users.write", "companies.read", "companies.write", "billing.read", "billing.write", "reports.read", "settings.write"}},
+	{"doctor", []string{"users.read", "companies.read", "reports.read"}},
+	{"receptionist", []string{"users.read", "companies.read", "billing.read"}},
+}
+
+var (
+	tenantPrefixes = []string{"Clínica", "Centro Médico", "Hospital", "Consultorios", "Policlínico"}
+	tenantNames    = []string{"San Rafael", "Los Andes", "Pichincha", "Santa Lucía", "El Ejido", "La Carolina", "Cumbayá", "Miraflores", "Del Valle", "Alborada"}
+	companySuffix  = []string{"S.A.", "Cía. Ltda.", "S.A.S."}
+	firstNames     = []string{"maria", "jose", "ana", "luis", "carmen", "jorge", "lucia", "diego", "sofia", "andres", "valeria", "pablo"}
+	lastNames      = []string{"gomez", "perez", "rodriguez", "torres", "vera", "mendoza", "castro", "salazar", "herrera", "ortiz"}
+)
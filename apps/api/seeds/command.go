@@ -0,0 +1,70 @@
+package seeds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+const commandUsage = `Usage: main seed [flags]
+
+Creates demo tenants, companies, roles and users. Running it again with the
+same flags leaves the database unchanged. The schema must be migrated first.
+
+Flags:
+  --seed N               value the generated names derive from (default 1)
+  --tenants N            number of tenants (default 3)
+  --users-per-company N  users created in each company (default 4)
+  --password P           password of every seeded user (default "pengi-demo")
+  --force                allow seeding when GIN_MODE=release
+`
+
+// RunCommand runs the seed command, writing its output to out. production
+// (GIN_MODE=release) refuses to seed unless --force is passed.
+func RunCommand(args []string, out io.Writer, connect func() (*gorm.DB, error), production bool) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() { fmt.Fprint(out, commandUsage) }
+
+	var opts Options
+	flags.Int64Var(&opts.Seed, "seed", 1, "")
+	flags.IntVar(&opts.Tenants, "tenants", 3, "")
+	flags.IntVar(&opts.UsersPerCompany, "users-per-company", 4, "")
+	flags.StringVar(&opts.Password, "password", "pengi-demo", "")
+	force := flags.Bool("force", false, "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+	if opts.Tenants < 0 || opts.UsersPerCompany < 0 {
+		return errors.New("--tenants and --users-per-company can't be negative")
+	}
+	if opts.Password == "" {
+		return errors.New("--password can't be empty")
+	}
+	if production && !*force {
+		return errors.New("refusing to seed demo data with GIN_MODE=release; pass --force to do it anyway")
+	}
+
+	db, err := connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+
+	summary, err := Seed(db, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Seeded %d tenant(s), %d company(ies), %d user(s) and %d environment(s) with seed %d\n",
+		summary.Tenants, summary.Companies, summary.Users, summary.Environments, opts.Seed)
+	fmt.Fprintf(out, "Log in as %q with password %q to access every company\n", DemoUserName, opts.Password)
+	return nil
+}
@@ -0,0 +1,325 @@
+package seeds
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"pengi-med-saas/core/auth"
+	"pengi-med-saas/core/database"
+	company_models "pengi-med-saas/features/companies/models"
+	permission_models "pengi-med-saas/features/permissions/models"
+	tenant_models "pengi-med-saas/features/tenants/models"
+	user_models "pengi-med-saas/features/users/models"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DemoUserName is a platform admin with the admin role in every seeded company.
+const DemoUserName = "demo"
+
+var slugSanitizer = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Options controls the size and shape of the seeded world.
+type Options struct {
+	// Seed makes the generated names reproducible.
+	Seed int64
+	// Tenants is how many tenants to create; each gets one or two companies.
+	Tenants int
+	// UsersPerCompany is how many users are created in each company.
+	UsersPerCompany int
+	// Password is set on every seeded user.
+	Password string
+}
+
+// Summary counts the records created by a run; existing records aren't counted.
+type Summary struct {
+	Tenants      int
+	Companies    int
+	Users        int
+	Environments int
+}
+
+/*
+Seed creates a demo world: the permission, feature, plan and role catalog,
+then tenants with companies spread across the plans and users holding roles in
+one or more companies.
+
+Everything is looked up by its natural key before being created, so running it
+again with the same options changes nothing. Patients and appointments will be
+seeded here once those features exist.
+*/
+func Seed(db *gorm.DB, opts Options) (Summary, error) {
+	var summary Summary
+	err := database.Primary(db).Transaction(func(tx *gorm.DB) error {
+		// A large seed can outlast DB_STATEMENT_TIMEOUT, which is meant for requests.
+		if err := tx.Exec("SET LOCAL statement_timeout = 0").Error; err != nil {
+			return err
+		}
+
+		before, err := count(tx)
+		if err != nil {
+			return err
+		}
+
+		s := &seeder{tx: tx, rng: rand.New(rand.NewSource(opts.Seed)), opts: opts}
+		if err := s.run(); err != nil {
+			return err
+		}
+
+		after, err := count(tx)
+		if err != nil {
+			return err
+		}
+		summary = Summary{
+			Tenants:      after.Tenants - before.Tenants,
+			Companies:    after.Companies - before.Companies,
+			Users:        after.Users - before.Users,
+			Environments: after.Environments - before.Environments,
+		}
+		return nil
+	})
+	return summary, err
+}
+
+func count(tx *gorm.DB) (Summary, error) {
+	var tenants, companies, users, environments int64
+	err := errors.Join(
+		tx.Model(&tenant_models.Tenant{}).Count(&tenants).Error,
+		tx.Model(&company_models.Company{}).Count(&companies).Error,
+		tx.Model(&user_models.User{}).Count(&users).Error,
+		tx.Model(&user_models.Environment{}).Count(&environments).Error,
+	)
+	return Summary{int(tenants), int(companies), int(users), int(environments)}, err
+}
+
+type seeder struct {
+	tx   *gorm.DB
+	rng  *rand.Rand
+	opts Options
+
+	passwordHash string
+	plans        map[string]*company_models.Plan
+	roles        map[string]*user_models.Role
+	usedNames    map[string]bool
+}
+
+func (s *seeder) run() error {
+	hash, err := auth.HashPassword(s.opts.Password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	s.passwordHash = hash
+	s.usedNames = map[string]bool{DemoUserName: true}
+
+	if err := s.seedCatalog(); err != nil {
+		return err
+	}
+
+	demo, err := s.user(DemoUserName)
+	if err != nil {
+		return err
+	}
+	if err := s.tx.Model(demo).Update("platform_admin", true).Error; err != nil {
+		return fmt.Errorf("user %s: %w", DemoUserName, err)
+	}
+
+	companyIndex := 0
+	for i := 0; i < s.opts.Tenants; i++ {
+		tenant, err := s.tenant(i)
+		if err != nil {
+			return err
+		}
+
+		var companies []*company_models.Company
+		for j := 0; j < 1+s.rng.Intn(2); j++ {
+			// Plans rotate so every plan is in use.
+			plan := plans[companyIndex%len(plans)]
+			companyIndex++
+
+			company, err := s.company(tenant, j, plan.Code)
+			if err != nil {
+				return err
+			}
+			if err := s.environment(demo, company, "admin"); err != nil {
+				return err
+			}
+			companies = append(companies, company)
+		}
+
+		for _, company := range companies {
+			for k := 0; k < s.opts.UsersPerCompany; k++ {
+				user, err := s.user(s.userName())
+				if err != nil {
+					return err
+				}
+				role := roles[k%len(roles)].Role
+				if err := s.environment(user, company, role); err != nil {
+					return err
+				}
+				// Staff of the first company also work at the tenant's other
+				// companies as receptionists.
+				if k == 0 && company == companies[0] {
+					for _, other := range companies[1:] {
+						if err := s.environment(user, other, "receptionist"); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *seeder) seedCatalog() error {
+	for _, p := range permissions {
+		permission := permission_models.Permission{
+			BaseStringID: database.BaseStringID{ID: p.ID},
+			Name:         p.Name,
+			Category:     p.Category,
+		}
+		if err := s.tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&permission).Error; err != nil {
+			return fmt.Errorf("permission %s: %w", p.ID, err)
+		}
+	}
+
+	featuresByCode := make(map[string]*company_models.Feature)
+	for _, f := range features {
+		feature := &company_models.Feature{}
+		if err := s.tx.Where(company_models.Feature{Code: f.Code}).
+			Attrs(company_models.Feature{Name: f.Name}).
+			FirstOrCreate(feature).Error; err != nil {
+			return fmt.Errorf("feature %s: %w", f.Code, err)
+		}
+		if err := s.tx.Model(feature).Association("Permissions").Append(permissionRefs(f.Permissions)); err != nil {
+			return fmt.Errorf("feature %s permissions: %w", f.Code, err)
+		}
+		featuresByCode[f.Code] = feature
+	}
+
+	s.plans = make(map[string]*company_models.Plan)
+	for _, p := range plans {
+		plan := &company_models.Plan{}
+		if err := s.tx.Omit(clause.Associations).Where(company_models.Plan{Code: p.Code}).
+			Attrs(company_models.Plan{Name: p.Name, Price: p.Price}).
+			FirstOrCreate(plan).Error; err != nil {
+			return fmt.Errorf("plan %s: %w", p.Code, err)
+		}
+		planFeatures := make([]*company_models.Feature, 0, len(p.Features))
+		for _, code := range p.Features {
+			planFeatures = append(planFeatures, featuresByCode[code])
+		}
+		if err := s.tx.Model(plan).Association("Features").Append(planFeatures); err != nil {
+			return fmt.Errorf("plan %s features: %w", p.Code, err)
+		}
+		s.plans[p.Code] = plan
+	}
+
+	s.roles = make(map[string]*user_models.Role)
+	for _, r := range roles {
+		role := &user_models.Role{}
+		if err := s.tx.Where(user_models.Role{Role: r.Role}).FirstOrCreate(role).Error; err != nil {
+			return fmt.Errorf("role %s: %w", r.Role, err)
+		}
+		if err := s.tx.Model(role).Association("Permissions").Append(permissionRefs(r.Permissions)); err != nil {
+			return fmt.Errorf("role %s permissions: %w", r.Role, err)
+		}
+		s.roles[r.Role] = role
+	}
+	return nil
+}
+
+func (s *seeder) tenant(i int) (*tenant_models.Tenant, error) {
+	name := fmt.Sprintf("%s %s", pick(s.rng, tenantPrefixes), pick(s.rng, tenantNames))
+	slug := strings.Trim(slugSanitizer.ReplaceAllString(strings.ToLower(asciiFold(name)), "-"), "-")
+	// The index keeps slugs unique when two tenants draw the same name.
+	slug = fmt.Sprintf("%s-%d", slug, i+1)
+
+	tenant := &tenant_models.Tenant{}
+	if err := s.tx.Where(tenant_models.Tenant{Slug: slug}).
+		Attrs(tenant_models.Tenant{Name: name}).
+		FirstOrCreate(tenant).Error; err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", slug, err)
+	}
+	return tenant, nil
+}
+
+func (s *seeder) company(tenant *tenant_models.Tenant, i int, planCode string) (*company_models.Company, error) {
+	tradeName := tenant.Name
+	if i > 0 {
+		tradeName = fmt.Sprintf("%s %s", tenant.Name, pick(s.rng, []string{"Norte", "Sur", "Valle", "Centro"}))
+	}
+	suffix := pick(s.rng, companySuffix)
+
+	company := &company_models.Company{}
+	if err := s.tx.Omit(clause.Associations).
+		Where(company_models.Company{TenantID: tenant.ID, TradeName: tradeName}).
+		Attrs(company_models.Company{LegalName: tradeName + " " + suffix, PlanCode: planCode}).
+		FirstOrCreate(company).Error; err != nil {
+		return nil, fmt.Errorf("company %s: %w", tradeName, err)
+	}
+
+	subscription := &company_models.Subscription{}
+	if err := s.tx.Omit(clause.Associations).
+		Where(company_models.Subscription{CompanyID: company.ID, PlanCode: company.PlanCode}).
+		Attrs(company_models.Subscription{Status: "active", ExpiresAt: time.Now().AddDate(1, 0, 0)}).
+		FirstOrCreate(subscription).Error; err != nil {
+		return nil, fmt.Errorf("subscription for %s: %w", tradeName, err)
+	}
+	return company, nil
+}
+
+// userName draws a name that isn't used yet in this run.
+func (s *seeder) userName() string {
+	base := pick(s.rng, firstNames) + "." + pick(s.rng, lastNames)
+	name := base
+	for n := 2; s.usedNames[name]; n++ {
+		name = fmt.Sprintf("%s%d", base, n)
+	}
+	s.usedNames[name] = true
+	return name
+}
+
+func (s *seeder) user(userName string) (*user_models.User, error) {
+	user := &user_models.User{}
+	if err := s.tx.Omit(clause.Associations).
+		Where(user_models.User{UserName: userName}).
+		Attrs(user_models.User{Password: s.passwordHash, Email: userName + "@pengi.test"}).
+		FirstOrCreate(user).Error; err != nil {
+		return nil, fmt.Errorf("user %s: %w", userName, err)
+	}
+	return user, nil
+}
+
+func (s *seeder) environment(user *user_models.User, company *company_models.Company, role string) error {
+	environment := &user_models.Environment{}
+	if err := s.tx.Omit(clause.Associations).
+		Where(user_models.Environment{UserID: user.ID, CompanyID: company.ID}).
+		Attrs(user_models.Environment{Name: company.TradeName, RoleID: s.roles[role].ID}).
+		FirstOrCreate(environment).Error; err != nil {
+		return fmt.Errorf("environment %s for %s: %w", company.TradeName, user.UserName, err)
+	}
+	return nil
+}
+
+func permissionRefs(ids []string) []permission_models.Permission {
+	refs := make([]permission_models.Permission, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, permission_models.Permission{BaseStringID: database.BaseStringID{ID: id}})
+	}
+	return refs
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
+
+var asciiReplacer = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n")
+
+func asciiFold(s string) string {
+	return asciiReplacer.Replace(s)
+}
@@ -0,0 +1,206 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"pengi-med-saas/core/auth"
+	"pengi-med-saas/core/envelope"
+	"pengi-med-saas/features/health"
+	"pengi-med-saas/routes"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Config is the configuration NewServer builds the API with. Tests may change
+// it in TestMain, before starting any server.
+var Config = routes.Config{
+	Auth: auth.Config{
+		Key:      "testutil-auth-key",
+		TokenTTL: 30 * time.Minute,
+	},
+	DefaultLang:        "es",
+	HealthCheckTimeout: health.DefaultTimeout,
+}
+
+// NewServer starts the full API, backed by db, on a local test server that is
+// closed when the test finishes.
+func NewServer(tb testing.TB, db *gorm.DB) *httptest.Server {
+	tb.Helper()
+
+	server := httptest.NewServer(routes.NewRouter(db, routes.NewServices(db, Config), Config, nil))
+	tb.Cleanup(server.Close)
+	return server
+}
+
+/*
+Client sends requests to a test server. It keeps cookies, so the refresh token
+set by login is sent back, and adds the bearer token and X-Tenant-Slug header
+once Login and WithTenant are used.
+*/
+type Client struct {
+	tb      testing.TB
+	server  *httptest.Server
+	http    *http.Client
+	token   string
+	tenant  string
+	headers http.Header
+}
+
+func NewClient(tb testing.TB, server *httptest.Server) *Client {
+	jar, _ := cookiejar.New(nil)
+	return &Client{
+		tb:     tb,
+		server: server,
+		http:   &http.Client{Jar: jar},
+	}
+}
+
+// WithTenant returns a copy of the client that sends X-Tenant-Slug: slug.
+func (c *Client) WithTenant(slug string) *Client {
+	clone := *c
+	clone.tenant = slug
+	return &clone
+}
+
+// WithHeader returns a copy of the client that also sends name: value, e.g.
+// If-None-Match. Login and WithTenant take precedence over it.
+func (c *Client) WithHeader(name, value string) *Client {
+	clone := *c
+	clone.headers = c.headers.Clone()
+	if clone.headers == nil {
+		clone.headers = http.Header{}
+	}
+	clone.headers.Set(name, value)
+	return &clone
+}
+
+// Login authenticates through /api/auth/login and fails the test if it is
+// rejected. Later requests carry the returned bearer token.
+func (c *Client) Login(userName, password string) *Client {
+	c.tb.Helper()
+
+	res := c.Post("/api/auth/login", map[string]string{
+		"user_name": userName,
+		"password":  password,
+	})
+	if res.StatusCode != http.StatusOK {
+		c.tb.Fatalf("testutil: login as %s failed with %d: %s", userName, res.StatusCode, res.Body)
+	}
+
+	var data struct {
+		Token string `json:"token"`
+	}
+	res.Decode(&data)
+	c.token = data.Token
+	return c
+}
+
+// Token returns the bearer token obtained by Login.
+func (c *Client) Token() string {
+	return c.token
+}
+
+func (c *Client) Get(path string) *Response {
+	return c.Do(http.MethodGet, path, nil)
+}
+
+func (c *Client) Post(path string, body any) *Response {
+	return c.Do(http.MethodPost, path, body)
+}
+
+func (c *Client) Put(path string, body any) *Response {
+	return c.Do(http.MethodPut, path, body)
+}
+
+func (c *Client) Delete(path string) *Response {
+	return c.Do(http.MethodDelete, path, nil)
+}
+
+// Do sends a request with body encoded as JSON, when not nil, and fails the
+// test on transport errors.
+func (c *Client) Do(method, path string, body any) *Response {
+	c.tb.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.tb.Fatalf("testutil: encoding request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.server.URL+path, reader)
+	if err != nil {
+		c.tb.Fatalf("testutil: building request: %v", err)
+	}
+	for name, values := range c.headers {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.tenant != "" {
+		req.Header.Set("X-Tenant-Slug", c.tenant)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		c.tb.Fatalf("testutil: %s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.tb.Fatalf("testutil: reading response: %v", err)
+	}
+	return &Response{tb: c.tb, StatusCode: res.StatusCode, Header: res.Header, Body: data}
+}
+
+// Response is a fully read HTTP response.
+type Response struct {
+	tb         testing.TB
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Envelope is the JSON body every API response shares.
+type Envelope struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	// Meta is only set on list responses.
+	Meta *envelope.Meta `json:"meta"`
+}
+
+// Envelope decodes the response body, failing the test if it isn't one.
+func (r *Response) Envelope() Envelope {
+	r.tb.Helper()
+
+	var env Envelope
+	if err := json.Unmarshal(r.Body, &env); err != nil {
+		r.tb.Fatalf("testutil: decoding response envelope: %v\n%s", err, r.Body)
+	}
+	return env
+}
+
+// Decode unmarshals the envelope's data into v and returns the envelope.
+func (r *Response) Decode(v any) Envelope {
+	r.tb.Helper()
+
+	env := r.Envelope()
+	if err := json.Unmarshal(env.Data, v); err != nil {
+		r.tb.Fatalf("testutil: decoding response data: %v\n%s", err, env.Data)
+	}
+	return env
+}
@@ -0,0 +1,32 @@
+/*
+Package testutil is the integration test harness for the API.
+
+Each test package gets its own Postgres database, migrated once with
+migrations.RunAllMigrations and cloned per test so tests can't see each
+other's data:
+
+	func TestMain(m *testing.M) { testutil.Main(m) }
+
+	func TestListUsers(t *testing.T) {
+		db := testutil.NewDatabase(t)
+		fixtures := testutil.LoadDefaultFixtures(t, db)
+
+		client := testutil.NewClient(t, testutil.NewServer(t, db)).
+			WithTenant("acme").
+			Login("alice", fixtures.Passwords["alice"])
+
+		res := client.Get("/api/users")
+		...
+	}
+
+The server comes from TEST_DATABASE_URL when it is set (any reachable Postgres,
+e.g. a container's socket or port; the user needs CREATEDB). Otherwise a
+throwaway cluster is started with the initdb and pg_ctl binaries found in
+PG_BIN, PATH or /usr/lib/postgresql/<version>/bin; initdb refuses to run as
+root, so root needs TEST_DATABASE_URL. Tests that need a database are skipped
+when neither is available.
+
+Tests for the routes package must live in package routes_test, since this
+package imports routes.
+*/
+package testutil
@@ -0,0 +1,194 @@
+package testutil
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"pengi-med-saas/core/auth"
+	"pengi-med-saas/core/database"
+	company_models "pengi-med-saas/features/companies/models"
+	permission_models "pengi-med-saas/features/permissions/models"
+	tenant_models "pengi-med-saas/features/tenants/models"
+	user_models "pengi-med-saas/features/users/models"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:embed fixtures/*.yaml
+var fixtureFiles embed.FS
+
+// Fixtures holds the records created by LoadFixtures, keyed by the names used
+// in the YAML files.
+type Fixtures struct {
+	Tenants   map[string]*tenant_models.Tenant   // by slug
+	Companies map[string]*company_models.Company // by trade name
+	Roles     map[string]*user_models.Role       // by role name
+	Users     map[string]*user_models.User       // by user name
+	// Passwords maps user names to their clear-text passwords.
+	Passwords map[string]string
+}
+
+type fixtureFile struct {
+	Tenants []struct {
+		Name        string `yaml:"name"`
+		Slug        string `yaml:"slug"`
+		DefaultLang string `yaml:"default_lang"`
+	} `yaml:"tenants"`
+	Companies []struct {
+		LegalName string `yaml:"legal_name"`
+		TradeName string `yaml:"trade_name"`
+		PlanCode  string `yaml:"plan_code"`
+		Tenant    string `yaml:"tenant"`
+	} `yaml:"companies"`
+	Roles []struct {
+		Role        string   `yaml:"role"`
+		Permissions []string `yaml:"permissions"`
+	} `yaml:"roles"`
+	Users []struct {
+		UserName      string `yaml:"user_name"`
+		Password      string `yaml:"password"`
+		Email         string `yaml:"email"`
+		PlatformAdmin bool   `yaml:"platform_admin"`
+		Environments  []struct {
+			Name    string `yaml:"name"`
+			Company string `yaml:"company"`
+			Role    string `yaml:"role"`
+		} `yaml:"environments"`
+	} `yaml:"users"`
+}
+
+// LoadDefaultFixtures loads testutil/fixtures/default.yaml.
+func LoadDefaultFixtures(tb testing.TB, db *gorm.DB) *Fixtures {
+	tb.Helper()
+
+	data, err := fixtureFiles.ReadFile("fixtures/default.yaml")
+	if err != nil {
+		tb.Fatalf("testutil: %v", err)
+	}
+
+	fixtures := newFixtures()
+	if err := fixtures.load(db, data); err != nil {
+		tb.Fatalf("testutil: loading default fixtures: %v", err)
+	}
+	return fixtures
+}
+
+/*
+LoadFixtures inserts the tenants, companies, roles and users described by the
+YAML files at paths, relative to the test's package directory. Later files can
+reference records from earlier ones.
+*/
+func LoadFixtures(tb testing.TB, db *gorm.DB, paths ...string) *Fixtures {
+	tb.Helper()
+
+	fixtures := newFixtures()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatalf("testutil: reading fixtures: %v", err)
+		}
+		if err := fixtures.load(db, data); err != nil {
+			tb.Fatalf("testutil: loading %s: %v", path, err)
+		}
+	}
+	return fixtures
+}
+
+func newFixtures() *Fixtures {
+	return &Fixtures{
+		Tenants:   make(map[string]*tenant_models.Tenant),
+		Companies: make(map[string]*company_models.Company),
+		Roles:     make(map[string]*user_models.Role),
+		Users:     make(map[string]*user_models.User),
+		Passwords: make(map[string]string),
+	}
+}
+
+func (f *Fixtures) load(db *gorm.DB, data []byte) error {
+	var file fixtureFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, t := range file.Tenants {
+			tenant := &tenant_models.Tenant{Name: t.Name, Slug: t.Slug, DefaultLang: t.DefaultLang}
+			if err := tx.Create(tenant).Error; err != nil {
+				return fmt.Errorf("tenant %s: %w", t.Slug, err)
+			}
+			f.Tenants[t.Slug] = tenant
+		}
+
+		for _, c := range file.Companies {
+			tenant, ok := f.Tenants[c.Tenant]
+			if !ok {
+				return fmt.Errorf("company %s: unknown tenant %q", c.TradeName, c.Tenant)
+			}
+			company := &company_models.Company{
+				LegalName: c.LegalName,
+				TradeName: c.TradeName,
+				PlanCode:  c.PlanCode,
+				TenantID:  tenant.ID,
+			}
+			if err := tx.Omit(clause.Associations).Create(company).Error; err != nil {
+				return fmt.Errorf("company %s: %w", c.TradeName, err)
+			}
+			f.Companies[c.TradeName] = company
+		}
+
+		for _, r := range file.Roles {
+			role := &user_models.Role{Role: r.Role}
+			for _, id := range r.Permissions {
+				role.Permissions = append(role.Permissions, permission_models.Permission{
+					BaseStringID: database.BaseStringID{ID: id},
+					Name:         id,
+				})
+			}
+			// Permissions shared between roles are inserted once.
+			if err := tx.Create(role).Error; err != nil {
+				return fmt.Errorf("role %s: %w", r.Role, err)
+			}
+			f.Roles[r.Role] = role
+		}
+
+		for _, u := range file.Users {
+			hash, err := auth.HashPassword(u.Password)
+			if err != nil {
+				return fmt.Errorf("user %s: %w", u.UserName, err)
+			}
+			user := &user_models.User{UserName: u.UserName, Password: hash, Email: u.Email, PlatformAdmin: u.PlatformAdmin}
+			if err := tx.Omit(clause.Associations).Create(user).Error; err != nil {
+				return fmt.Errorf("user %s: %w", u.UserName, err)
+			}
+
+			for _, e := range u.Environments {
+				company, ok := f.Companies[e.Company]
+				if !ok {
+					return fmt.Errorf("user %s: unknown company %q", u.UserName, e.Company)
+				}
+				role, ok := f.Roles[e.Role]
+				if !ok {
+					return fmt.Errorf("user %s: unknown role %q", u.UserName, e.Role)
+				}
+				environment := user_models.Environment{
+					UserID:    user.ID,
+					Name:      e.Name,
+					RoleID:    role.ID,
+					Role:      *role,
+					CompanyID: company.ID,
+				}
+				if err := tx.Omit(clause.Associations).Create(&environment).Error; err != nil {
+					return fmt.Errorf("user %s environment %s: %w", u.UserName, e.Name, err)
+				}
+				user.Environments = append(user.Environments, environment)
+			}
+
+			f.Users[u.UserName] = user
+			f.Passwords[u.UserName] = u.Password
+		}
+		return nil
+	})
+}
@@ -0,0 +1,279 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"pengi-med-saas/core/logger"
+	"pengi-med-saas/migrations"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gorm_logger "gorm.io/gorm/logger"
+)
+
+var (
+	errNoPostgres = errors.New("no Postgres available: set TEST_DATABASE_URL or install initdb/pg_ctl")
+	// initdb refuses to run as root, e.g. in CI containers.
+	errRunningAsRoot = errors.New("initdb can't run as root: set TEST_DATABASE_URL to test against a database")
+
+	// pkgServer is the server shared by the test package; nil when none is available.
+	pkgServer *server
+	// skipReason explains why tests needing a database are skipped.
+	skipReason string
+	databases  atomic.Int64
+)
+
+/*
+Main runs the package's tests against a private Postgres database. Call it
+from TestMain:
+
+	func TestMain(m *testing.M) { testutil.Main(m) }
+*/
+func Main(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	setupEnvironment()
+
+	srv, err := startServer()
+	if err != nil {
+		skipReason = err.Error()
+		if !errors.Is(err, errNoPostgres) && !errors.Is(err, errRunningAsRoot) {
+			fmt.Fprintln(os.Stderr, "testutil:", err)
+		}
+		return m.Run()
+	}
+	defer srv.stop()
+
+	if err := srv.prepareTemplate(); err != nil {
+		fmt.Fprintln(os.Stderr, "testutil:", err)
+		return 1
+	}
+	pkgServer = srv
+	return m.Run()
+}
+
+// setupEnvironment quiets gin and the logger for tests.
+func setupEnvironment() {
+	gin.SetMode(gin.TestMode)
+	if logger.Log == nil {
+		logger.Log = zap.NewNop()
+	}
+}
+
+/*
+NewDatabase returns a connection to a new database cloned from the package's
+migrated template. It is dropped when the test finishes. The test is skipped
+when no Postgres server is available.
+*/
+func NewDatabase(tb testing.TB) *gorm.DB {
+	tb.Helper()
+
+	if pkgServer == nil {
+		if skipReason == "" {
+			skipReason = "testutil.Main was not called from TestMain"
+		}
+		tb.Skip(skipReason)
+	}
+
+	name := fmt.Sprintf("%s_%d", pkgServer.template, databases.Add(1))
+	if err := pkgServer.exec(fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s",
+		pgx.Identifier{name}.Sanitize(), pgx.Identifier{pkgServer.template}.Sanitize())); err != nil {
+		tb.Fatalf("testutil: creating database %s: %v", name, err)
+	}
+
+	db, err := open(pkgServer.dsn(name))
+	if err != nil {
+		tb.Fatalf("testutil: connecting to %s: %v", name, err)
+	}
+
+	tb.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		if err := pkgServer.exec("DROP DATABASE IF EXISTS " + pgx.Identifier{name}.Sanitize() + " WITH (FORCE)"); err != nil {
+			tb.Logf("testutil: dropping database %s: %v", name, err)
+		}
+	})
+	return db
+}
+
+type server struct {
+	// dsn returns the connection string for the named database.
+	dsn      func(dbname string) string
+	template string
+	stop     func()
+}
+
+// startServer connects to TEST_DATABASE_URL or starts a local cluster.
+func startServer() (*server, error) {
+	template := fmt.Sprintf("pengi_test_%d_%d", os.Getpid(), time.Now().Unix())
+
+	if raw := os.Getenv("TEST_DATABASE_URL"); raw != "" {
+		base, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TEST_DATABASE_URL: %w", err)
+		}
+		srv := &server{
+			template: template,
+			dsn: func(dbname string) string {
+				u := *base
+				u.Path = "/" + dbname
+				return u.String()
+			},
+		}
+		srv.stop = srv.dropTemplate
+		return srv, nil
+	}
+
+	return startLocalCluster(template)
+}
+
+// startLocalCluster runs initdb and pg_ctl in a temporary directory. The
+// server only listens on a Unix socket in that directory.
+func startLocalCluster(template string) (*server, error) {
+	binDir, err := findPostgresBin()
+	if err != nil {
+		return nil, err
+	}
+	if os.Geteuid() == 0 {
+		return nil, errRunningAsRoot
+	}
+
+	dir, err := os.MkdirTemp("", "pengi-pg-")
+	if err != nil {
+		return nil, err
+	}
+	dataDir := filepath.Join(dir, "data")
+
+	initdb := exec.Command(filepath.Join(binDir, "initdb"),
+		"-D", dataDir, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-sync")
+	if out, err := initdb.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initdb failed: %w\n%s", err, out)
+	}
+
+	pgCtl := filepath.Join(binDir, "pg_ctl")
+	start := exec.Command(pgCtl, "-D", dataDir, "-l", filepath.Join(dir, "postgres.log"), "-w",
+		"-o", fmt.Sprintf("-k %s -c listen_addresses='' -F", dir), "start")
+	if out, err := start.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("pg_ctl start failed: %w\n%s", err, out)
+	}
+
+	return &server{
+		template: template,
+		dsn: func(dbname string) string {
+			return fmt.Sprintf("host=%s port=5432 user=postgres dbname=%s sslmode=disable", dir, dbname)
+		},
+		stop: func() {
+			exec.Command(pgCtl, "-D", dataDir, "-m", "immediate", "stop").Run()
+			os.RemoveAll(dir)
+		},
+	}, nil
+}
+
+func findPostgresBin() (string, error) {
+	if dir := os.Getenv("PG_BIN"); dir != "" {
+		return dir, nil
+	}
+	if initdb, err := exec.LookPath("initdb"); err == nil {
+		return filepath.Dir(initdb), nil
+	}
+	// Debian and Ubuntu keep the server binaries off PATH.
+	matches, _ := filepath.Glob("/usr/lib/postgresql/*/bin/initdb")
+	if len(matches) == 0 {
+		return "", errNoPostgres
+	}
+	sort.Strings(matches)
+	return filepath.Dir(matches[len(matches)-1]), nil
+}
+
+// prepareTemplate creates the template database and migrates it, so every
+// NewDatabase is a cheap copy instead of a full migration run.
+func (s *server) prepareTemplate() error {
+	if err := s.exec("CREATE DATABASE " + pgx.Identifier{s.template}.Sanitize()); err != nil {
+		return fmt.Errorf("creating template database: %w", err)
+	}
+
+	db, err := open(s.dsn(s.template))
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	// The message sync reads i18n/messages relative to the working directory.
+	return inModuleRoot(func() error {
+		return migrations.RunAllMigrations(db)
+	})
+}
+
+func (s *server) dropTemplate() {
+	s.exec("DROP DATABASE IF EXISTS " + pgx.Identifier{s.template}.Sanitize() + " WITH (FORCE)")
+}
+
+// exec runs a statement on the maintenance database.
+func (s *server) exec(query string) error {
+	db, err := open(s.dsn("postgres"))
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err = sqlDB.ExecContext(ctx, query)
+	return err
+}
+
+func open(dsn string) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gorm_logger.Discard})
+}
+
+// inModuleRoot runs fn with the working directory set to the directory that
+// holds go.mod.
+func inModuleRoot(fn func() error) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	root := wd
+	for {
+		if _, err := os.Stat(filepath.Join(root, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return fmt.Errorf("go.mod not found above %s", wd)
+		}
+		root = parent
+	}
+
+	if err := os.Chdir(root); err != nil {
+		return err
+	}
+	defer os.Chdir(wd)
+	return fn()
+}